@@ -0,0 +1,150 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AbandonReport describes the children abandoned by
+// WaitForChildrenOrAbandon().
+type AbandonReport struct {
+	Children []AbandonedChild
+}
+
+// AbandonedChild describes a child that did not finish in time.
+type AbandonedChild struct {
+	// Name given with WithName(), if any.
+	Name string
+
+	// Same as the String() representation of the child.
+	Description string
+
+	// Time since the child was created.
+	Age time.Duration
+
+	// Number of Finished() calls that were still pending.
+	Pending int
+
+	// Creation stack of the child (only in debug mode, see SetDebug()).
+	Stack string
+}
+
+// String returns a multi-line description of the abandoned children, suitable
+// for logging.
+func (r AbandonReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d abandoned children", len(r.Children))
+
+	for _, child := range r.Children {
+		fmt.Fprintf(&b, "\n  %s (age %s, %d pending)", child.Description,
+			child.Age.Truncate(time.Millisecond), child.Pending)
+		if child.Stack != "" {
+			b.WriteString(strings.ReplaceAll(child.Stack, "\n", "\n  "))
+		}
+	}
+
+	return b.String()
+}
+
+// WaitForChildrenOrAbandon is like ctx.WaitForChildren() but waits at most d.
+// Children that did not finish by then are abandoned: they are detached from
+// ctx (as if they called Finished()) and described in the returned report.
+// Any Finished() calls they make later are ignored. Pending registrations
+// that have no child context (functions registered with Defer() and children
+// that became unreachable) are also detached and reported as anonymous
+// entries. If ctx was not created by
+// this package, it just calls ctx.WaitForChildren() and returns an empty
+// report.
+func WaitForChildrenOrAbandon(ctx Context, d time.Duration) AbandonReport {
+	c := lookup(ctx)
+	if c == nil {
+		ctx.WaitForChildren()
+		return AbandonReport{}
+	}
+
+	if !checkWait(ctx, "WaitForChildrenOrAbandon") {
+		return AbandonReport{}
+	}
+
+	start := beginWait()
+	defer func() {
+		c.recordWait(ctx, time.Since(start))
+	}()
+
+	timeout := make(chan struct{})
+	stop := c.getClock().AfterFunc(d, func() {
+		close(timeout)
+	})
+	defer stop()
+
+	if c.childrenWg.waitUntil(timeout) {
+		return AbandonReport{}
+	}
+
+	var report AbandonReport
+	for _, child := range c.pendingChildren(nil) {
+		pending := int(child.waits.Load())
+		if pending == 0 {
+			// Finished in the meantime.
+			continue
+		}
+
+		var stack strings.Builder
+		writeStack(&stack, "created at", child.stackOf())
+
+		report.Children = append(report.Children, AbandonedChild{
+			Name:        child.name,
+			Description: child.String(),
+			Age:         time.Since(child.created),
+			Pending:     pending,
+			Stack:       stack.String(),
+		})
+
+		child.abandon()
+	}
+
+	// Registrations without a child context: functions registered with
+	// Defer() and children that became unreachable without finishing.
+	if n := c.abandonDefers(); n > 0 {
+		report.Children = append(report.Children, AbandonedChild{
+			Description: "deferred functions",
+			Pending:     n,
+		})
+	}
+
+	if n := c.childrenWg.count(); n > 0 {
+		c.childrenWg.detach(n)
+
+		report.Children = append(report.Children, AbandonedChild{
+			Description: "unreachable children",
+			Pending:     n,
+		})
+	}
+
+	return report
+}
+
+// pendingChildren appends to children all contexts in the tree rooted at c
+// that report to c and still have pending Finished() calls.
+func (c *ctxImpl) pendingChildren(children []*ctxImpl) []*ctxImpl {
+	for _, kid := range c.liveKids() {
+		if kid.pNode() == c.node && kid.waits.Load() > 0 {
+			children = append(children, kid)
+		}
+
+		children = kid.pendingChildren(children)
+	}
+
+	return children
+}
+
+// abandon calls Finished() for all pending registrations of c on its behalf.
+// Any Finished() calls made later by the child itself are ignored.
+func (c *ctxImpl) abandon() {
+	c.abandoned.Store(true)
+
+	for c.waits.Load() > 0 {
+		c.Finished()
+	}
+}
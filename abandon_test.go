@@ -0,0 +1,109 @@
+package context
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForChildrenOrAbandon(t *testing.T) {
+	parent := Background()
+
+	fast := EnableWait(WithName(parent, "fast"))
+	stuck := EnableWait(WithName(parent, "stuck"))
+
+	go fast.Finished()
+
+	report := WaitForChildrenOrAbandon(parent, 10*time.Millisecond)
+
+	if len(report.Children) != 1 {
+		t.Fatalf("Expected 1 abandoned child. Got %d.", len(report.Children))
+	}
+
+	child := report.Children[0]
+	if child.Name != "stuck" {
+		t.Errorf("Expected abandoned child to be %q. Got %q.", "stuck", child.Name)
+	}
+	if child.Pending != 1 {
+		t.Errorf("Expected 1 pending Finished() call. Got %d.", child.Pending)
+	}
+	if !strings.Contains(report.String(), "stuck") {
+		t.Errorf("Expected report to mention the abandoned child. Got %q.", report.String())
+	}
+
+	// Abandoned children no longer block waits and can still call
+	// Finished() (which would otherwise panic as a misuse).
+	parent.WaitForChildren()
+	stuck.Finished()
+}
+
+func TestWaitForChildrenOrAbandon_Anonymous(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+
+	ran := make(chan struct{})
+	parent.Defer(func() { close(ran) })
+
+	// Registered but unreachable, so it can never finish.
+	func() {
+		EnableWait(WithName(parent, "lost"))
+	}()
+	runtime.GC()
+
+	report := WaitForChildrenOrAbandon(parent, 10*time.Millisecond)
+
+	pending := 0
+	for _, child := range report.Children {
+		if child.Name != "" {
+			t.Errorf("Expected only anonymous entries. Got %q.", child.Name)
+		}
+		pending += child.Pending
+	}
+	if pending != 2 {
+		t.Errorf("Expected 2 abandoned registrations. Got %d.", pending)
+	}
+
+	parent.WaitForChildren()
+
+	// The deferred function still runs, without affecting new waits.
+	child := EnableWait(WithName(parent, "new"))
+
+	cancel()
+	<-ran
+
+	if n := lookup(parent).childrenWg.count(); n != 1 {
+		t.Errorf("Expected 1 pending child. Got %d.", n)
+	}
+
+	child.Finished()
+	parent.WaitForChildren()
+}
+
+func TestWaitForChildrenOrAbandon_AllFinished(t *testing.T) {
+	parent := Background()
+
+	child := EnableWait(WithName(parent, "child"))
+	go child.Finished()
+
+	report := WaitForChildrenOrAbandon(parent, 1*time.Second)
+	if len(report.Children) != 0 {
+		t.Errorf("Expected no abandoned children. Got %v.", report.Children)
+	}
+}
+
+func TestWaitForChildrenOrAbandon_Stack(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	parent := Background()
+	EnableWait(WithName(parent, "child"))
+
+	report := WaitForChildrenOrAbandon(parent, 0)
+	if len(report.Children) != 1 {
+		t.Fatalf("Expected 1 abandoned child. Got %d.", len(report.Children))
+	}
+
+	if !strings.Contains(report.Children[0].Stack, "TestWaitForChildrenOrAbandon_Stack") {
+		t.Errorf("Expected stack to include the test function. Got %q.", report.Children[0].Stack)
+	}
+}
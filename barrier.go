@@ -0,0 +1,82 @@
+package context
+
+import (
+	"sync"
+)
+
+// Barrier allows a fixed number of sibling children to rendezvous at phase
+// boundaries. Each child calls Wait() when it reaches the boundary and it
+// blocks until all of them do so. A Barrier can be reused for any number of
+// phases.
+//
+// Waiting is cancellation aware. If either the context passed to Wait() or
+// the Context the Barrier was obtained from is done, Wait() returns the
+// corresponding error.
+type Barrier struct {
+	ctx Context
+	n   int
+
+	mu      sync.Mutex
+	arrived int
+
+	// Closed when all children arrived at the current phase.
+	release chan struct{}
+}
+
+func (c *ctxImpl) NewBarrier(n int) *Barrier {
+	if n <= 0 {
+		panic("tried to create a barrier with a non-positive number of children")
+	}
+
+	return &Barrier{
+		ctx:     c,
+		n:       n,
+		release: make(chan struct{}),
+	}
+}
+
+// Wait blocks until all children called Wait() for the current phase. It
+// returns nil if the barrier was reached or an error if ctx or the Context
+// the Barrier was obtained from is done before that. A child that got an
+// error is not counted as having arrived.
+func (b *Barrier) Wait(ctx Context) error {
+	b.mu.Lock()
+
+	release := b.release
+
+	b.arrived++
+	if b.arrived == b.n {
+		// Last one to arrive. Start a new phase and release everybody.
+		b.arrived = 0
+		b.release = make(chan struct{})
+		b.mu.Unlock()
+
+		close(release)
+
+		return nil
+	}
+
+	b.mu.Unlock()
+
+	var err error
+	select {
+	case <-release:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-b.ctx.Done():
+		err = b.ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.release != release {
+		// The barrier was reached while we were giving up.
+		return nil
+	}
+
+	b.arrived--
+
+	return err
+}
@@ -0,0 +1,62 @@
+package context
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBarrier(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	const numChildren = 3
+
+	b := ctx.NewBarrier(numChildren)
+
+	var phase1 atomic.Int32
+	failed := atomic.Bool{}
+
+	for i := 0; i < numChildren; i++ {
+		go func(ctx Context) {
+			defer ctx.Finished()
+
+			phase1.Add(1)
+			if err := b.Wait(ctx); err != nil {
+				t.Errorf("Unexpected error %v.", err)
+			}
+
+			// All children must have finished phase 1.
+			if phase1.Load() != numChildren {
+				failed.Store(true)
+			}
+
+			if err := b.Wait(ctx); err != nil {
+				t.Errorf("Unexpected error %v.", err)
+			}
+		}(EnableWait(ctx))
+	}
+
+	parent.WaitForChildren()
+
+	if failed.Load() {
+		t.Errorf("Expected all children to reach the barrier before continuing.")
+	}
+}
+
+func TestBarrier_Canceled(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	b := ctx.NewBarrier(2)
+
+	go func() {
+		time.Sleep(1 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := b.Wait(ctx); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+}
@@ -0,0 +1,95 @@
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func BenchmarkWithCancel(b *testing.B) {
+	parent := Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, cancel := WithCancel(parent)
+		cancel()
+	}
+}
+
+func BenchmarkWithCancel_Depth(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ctx := Background()
+
+				cancels := make([]CancelFunc, depth)
+				for j := range cancels {
+					ctx, cancels[j] = WithCancel(ctx)
+				}
+
+				for _, cancel := range cancels {
+					cancel()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEnableWaitFinished(b *testing.B) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EnableWait(ctx).Finished()
+	}
+}
+
+func BenchmarkEnableWaitFinished_Parallel(b *testing.B) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			EnableWait(ctx).Finished()
+		}
+	})
+}
+
+type benchKey struct{}
+
+type benchLevelKey int
+
+func BenchmarkValue_Depth(b *testing.B) {
+	for _, depth := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			// Each level adds its own value layer, so lookups of the
+			// root value have to walk all of them.
+			ctx := Adopt(stdcontext.WithValue(Background(), benchKey{}, 1))
+			for j := 0; j < depth; j++ {
+				ctx = WithValues(ctx, benchLevelKey(j), j)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if ctx.Value(benchKey{}) == nil {
+					b.Fatal("missing value")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWithTimeout(b *testing.B) {
+	parent := Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, cancel := WithTimeout(parent, time.Hour)
+		cancel()
+	}
+}
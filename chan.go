@@ -0,0 +1,36 @@
+package context
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned by Recv() when the channel is closed.
+var ErrClosed = errors.New("context: channel closed")
+
+// Recv receives a value from ch, giving up if ctx is done first. It returns
+// ctx.Err() if ctx is done and ErrClosed if ch is closed.
+func Recv[T any](ctx context.Context, ch <-chan T) (T, error) {
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return v, ErrClosed
+		}
+
+		return v, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Send sends v to ch, giving up if ctx is done first, in which case it
+// returns ctx.Err().
+func Send[T any](ctx context.Context, ch chan<- T, v T) error {
+	select {
+	case ch <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
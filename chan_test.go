@@ -0,0 +1,36 @@
+package context
+
+import (
+	"testing"
+)
+
+func TestRecvSend(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	ch := make(chan int, 1)
+
+	if err := Send(ctx, ch, 1); err != nil {
+		t.Errorf("Unexpected error %v.", err)
+	}
+	if v, err := Recv(ctx, ch); err != nil || v != 1 {
+		t.Errorf("Expected to receive 1. Got %d (%v).", v, err)
+	}
+
+	cancel()
+
+	if _, err := Recv(ctx, ch); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+
+	ch <- 2
+	if err := Send(ctx, ch, 3); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+
+	<-ch
+	close(ch)
+
+	if _, err := Recv(Background(), ch); err != ErrClosed {
+		t.Errorf("Expected error to be %v. Got %v.", ErrClosed, err)
+	}
+}
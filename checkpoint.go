@@ -0,0 +1,49 @@
+package context
+
+// Checkpoint returns ctx.Err(). It exists so tight loops can poll for
+// cancellation with a consistent API:
+//
+//	for _, item := range items {
+//		if err := context.Checkpoint(ctx); err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Checkpoint(ctx Context) error {
+	return ctx.Err()
+}
+
+// Checkpointer is a cheaper version of Checkpoint() for very tight loops. It
+// only actually checks the context once every n calls.
+type Checkpointer struct {
+	ctx Context
+
+	n     int
+	count int
+}
+
+// CheckpointEvery returns a Checkpointer that checks ctx once every n calls
+// to Check(). A Checkpointer must not be used concurrently.
+func CheckpointEvery(ctx Context, n int) *Checkpointer {
+	if n <= 0 {
+		panic("tried to create a checkpointer with a non-positive interval")
+	}
+
+	return &Checkpointer{
+		ctx: ctx,
+		n:   n,
+	}
+}
+
+// Check returns the context error if this call is a multiple of the
+// checkpoint interval and the context is done. It returns nil otherwise.
+func (c *Checkpointer) Check() error {
+	c.count++
+	if c.count < c.n {
+		return nil
+	}
+
+	c.count = 0
+
+	return c.ctx.Err()
+}
@@ -0,0 +1,37 @@
+package context
+
+import (
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	if err := Checkpoint(ctx); err != nil {
+		t.Errorf("Unexpected error %v.", err)
+	}
+
+	cancel()
+
+	if err := Checkpoint(ctx); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+}
+
+func TestCheckpointEvery(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	cancel()
+
+	c := CheckpointEvery(ctx, 3)
+
+	for i := 1; i <= 6; i++ {
+		err := c.Check()
+		if i%3 == 0 {
+			if err != Canceled {
+				t.Errorf("Expected error to be %v at call %d. Got %v.", Canceled, i, err)
+			}
+		} else if err != nil {
+			t.Errorf("Unexpected error %v at call %d.", err, i)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package context
+
+import (
+	"time"
+)
+
+// Clock is a source of time used to track deadlines. It makes it possible to
+// test timeout related code without actually waiting (see the ctxtest
+// package for a manually advanced implementation).
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc arranges for f to be called once d elapsed. The returned
+	// function stops the timer and returns true if the call stopped it
+	// before f was called (see time.Timer.Stop()).
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// realClock is the Clock used by default. It uses the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// WithClock returns a copy of parent that uses the given clock to track the
+// deadlines of contexts created from it (directly or indirectly) with
+// WithDeadline() or WithTimeout(). Deadlines of contexts created before (like
+// parent itself) are not affected.
+func WithClock(parent Context, clock Clock) Context {
+	c := newCtx(stdContext(parent), parent)
+	c.setInherited(func(i *inherited) {
+		i.clock = clock
+	})
+
+	derived(parent, c, KindOther)
+
+	return c
+}
+
+// getClock returns the Clock to use for deadlines of contexts derived from
+// n, which might be nil.
+func (n *node) getClock() Clock {
+	clock := n.config().clock
+	if clock == nil {
+		return realClock{}
+	}
+
+	return clock
+}
@@ -0,0 +1,70 @@
+package context
+
+import (
+	stdcontext "context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a minimal Clock advanced by hand (see ctxtest.Clock for the
+// full implementation).
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+	fns []func()
+}
+
+func (m *manualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+func (m *manualClock) AfterFunc(d time.Duration, f func()) func() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fns = append(m.fns, f)
+
+	return func() bool { return false }
+}
+
+func (m *manualClock) fire() {
+	m.mu.Lock()
+	fns := m.fns
+	m.fns = nil
+	m.mu.Unlock()
+
+	for _, f := range fns {
+		f()
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	parent := WithClock(Background(), clock)
+
+	ctx, cancel := WithTimeout(WithName(parent, "child"), time.Hour)
+	defer cancel()
+
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(clock.now.Add(time.Hour)) {
+		t.Errorf("Expected deadline to be %v. Got %v.", clock.now.Add(time.Hour), deadline)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected no error. Got %v.", ctx.Err())
+	}
+
+	clock.fire()
+
+	<-ctx.Done()
+
+	if ctx.Err() != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, ctx.Err())
+	}
+	if cause := stdcontext.Cause(ctx); cause != DeadlineExceeded {
+		t.Errorf("Expected cause to be %v. Got %v.", DeadlineExceeded, cause)
+	}
+}
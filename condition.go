@@ -0,0 +1,93 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrConditionMet is the cause of the cancellation of contexts created with
+// WithCondition() or WithChannel() when their external condition fires.
+var ErrConditionMet = errors.New("context: condition met")
+
+// WithCondition returns a copy of parent that is canceled (with cause
+// ErrConditionMet) as soon as cond returns true. cond is checked when the
+// context is created and then every poll interval (using the clock of parent,
+// see WithClock()) until the context is done. This integrates non-context
+// signals (feature flags, leadership loss, etc) into the tree.
+//
+// Calling the returned CancelFunc cancels the context and stops polling.
+func WithCondition(parent Context, cond func() bool, poll time.Duration) (Context, CancelFunc) {
+	if poll <= 0 {
+		panic("non-positive poll interval for WithCondition")
+	}
+
+	c, cancel := newConditionCtx(parent)
+
+	clock := c.getClock()
+
+	var mu sync.Mutex
+	var stop func() bool
+
+	var check func()
+	check = func() {
+		if cond() {
+			c.cancelWith(ErrConditionMet)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if c.Err() == nil {
+			stop = clock.AfterFunc(poll, check)
+		}
+	}
+
+	check()
+
+	context.AfterFunc(c, func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if stop != nil {
+			stop()
+		}
+	})
+
+	derived(parent, c, KindCancel)
+
+	return c, cancel
+}
+
+// WithChannel returns a copy of parent that is canceled (with cause
+// ErrConditionMet) when ch is closed or receives a value.
+//
+// Calling the returned CancelFunc cancels the context and stops watching ch.
+func WithChannel(parent Context, ch <-chan struct{}) (Context, CancelFunc) {
+	c, cancel := newConditionCtx(parent)
+
+	go func() {
+		select {
+		case <-ch:
+			c.cancelWith(ErrConditionMet)
+		case <-c.Done():
+		}
+	}()
+
+	derived(parent, c, KindCancel)
+
+	return c, cancel
+}
+
+// newConditionCtx returns a cancelable copy of parent.
+func newConditionCtx(parent Context) (*ctxImpl, CancelFunc) {
+	ctx, cancel := context.WithCancelCause(stdContext(parent))
+
+	c := newCtx(ctx, parent)
+	c.cancel = cancel
+	c.track()
+
+	return c, func() { c.cancelWith(nil) }
+}
@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithCondition(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	var leader atomic.Bool
+	leader.Store(true)
+
+	ctx, cancel := WithCondition(WithClock(Background(), clock), func() bool {
+		return !leader.Load()
+	}, time.Second)
+	defer cancel()
+
+	clock.fire()
+	if ctx.Err() != nil {
+		t.Fatalf("Expected no error. Got %v.", ctx.Err())
+	}
+
+	leader.Store(false)
+	clock.fire()
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, ctx.Err())
+	}
+	if cause := context.Cause(ctx); cause != ErrConditionMet {
+		t.Errorf("Expected cause to be %v. Got %v.", ErrConditionMet, cause)
+	}
+}
+
+func TestWithCondition_AlreadyMet(t *testing.T) {
+	ctx, cancel := WithCondition(Background(), func() bool {
+		return true
+	}, time.Hour)
+	defer cancel()
+
+	if cause := context.Cause(ctx); cause != ErrConditionMet {
+		t.Errorf("Expected cause to be %v. Got %v.", ErrConditionMet, cause)
+	}
+}
+
+func TestWithChannel(t *testing.T) {
+	ch := make(chan struct{})
+
+	ctx, cancel := WithChannel(Background(), ch)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		t.Fatalf("Expected no error. Got %v.", ctx.Err())
+	}
+
+	close(ch)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Expected context to be canceled.")
+	}
+
+	if cause := context.Cause(ctx); cause != ErrConditionMet {
+		t.Errorf("Expected cause to be %v. Got %v.", ErrConditionMet, cause)
+	}
+}
+
+func TestWithChannel_Cancel(t *testing.T) {
+	ctx, cancel := WithChannel(Background(), make(chan struct{}))
+	cancel()
+
+	if cause := context.Cause(ctx); cause != Canceled {
+		t.Errorf("Expected cause to be %v. Got %v.", Canceled, cause)
+	}
+}
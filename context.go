@@ -21,7 +21,11 @@ package context
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -34,6 +38,12 @@ var (
 // Context behaves exactly like a standard library Context but also includes
 // support for waiting on derived (child) Contexts.
 //
+// The interface only has exported methods so it can be implemented outside of
+// this package (for example, by mocks or by wrappers adding extra behavior).
+// Wait support is found through the Value() method (see Adopt()) so
+// implementations that delegate Value() to a Context created by this package
+// transparently support waiting.
+//
 // See https://golang.org/pkg/context/#Context.
 type Context interface {
 	context.Context
@@ -43,44 +53,599 @@ type Context interface {
 	// using the waiting feature.
 	Finished()
 
+	// FinishedErr is like Finished() but also reports the given error (if
+	// not nil) to the parent Context as a *ChildError. Errors reported by
+	// children can be retrieved with ChildrenErr().
+	FinishedErr(err error)
+
+	// ChildrenErr returns all errors reported by children through
+	// FinishedErr() joined together (see errors.Join) or nil if there were
+	// none.
+	ChildrenErr() error
+
+	// FirstError returns the first error reported by a child through
+	// FinishedErr() (as a *ChildError) or nil if there was none. It does
+	// not block and it does not cancel anything, so it can be used to check
+	// if anything went wrong while letting other children run.
+	FirstError() error
+
 	// Wait waits on all immediate children to finish their work. It blocks
 	// until all children report that their work is finished.
+	//
+	// It can be called concurrently and a Context can be reused for any
+	// number of sequential waves of EnableWait()/WaitForChildren() calls.
 	WaitForChildren()
 
-	context() context.Context
+	// Parent returns the Context this Context was derived from or nil if
+	// this is a root Context.
+	Parent() Context
+
+	// Root returns the root Context of the tree this Context belongs to. For
+	// root Contexts, it returns the Context itself.
+	Root() Context
+
+	// Unwrap returns the standard library context embedded in this Context.
+	// It can be used with code that type asserts or compares contexts (for
+	// example, ctx.Unwrap() == context.Background() for a Context returned by
+	// Background()).
+	//
+	// The returned context behaves exactly like this Context (same values,
+	// deadline and cancellation) but might not carry its wait state.
+	Unwrap() context.Context
+
+	// AfterChildrenFinished registers a function to be called after all
+	// children finished their work. Registered functions are called exactly
+	// once, in registration order, after the last child calls Finished() and
+	// before WaitForChildren() returns.
+	AfterChildrenFinished(fn func())
+
+	// NewBarrier returns a Barrier that can be used by n children of this
+	// Context to rendezvous at phase boundaries.
+	NewBarrier(n int) *Barrier
+
+	// Store returns the mutable key/value Store associated with this
+	// Context.
+	Store() *Store
+
+	// SetLocal associates value with key in this Context. The given cleanup
+	// function (if not nil) is called after this Context is done and all its
+	// children finished their work, making it possible to tie resources (temp
+	// files, connections, etc) to the lifetime of the Context.
+	SetLocal(key, value any, cleanup func())
+
+	// Local returns the value associated with key by SetLocal() in this
+	// Context or in the nearest ancestor that has it. The boolean result
+	// reports whether the key was found.
+	Local(key any) (any, bool)
+
+	// RegisterCleanup registers fn to be called after this Context is done
+	// and all its children finished their work, with the cause of the
+	// cancellation (see context.Cause()). Like with t.Cleanup(), functions
+	// are called in reverse registration order (cleanup functions passed to
+	// SetLocal() included).
+	RegisterCleanup(fn func(err error))
+
+	// OnCancel registers fn to be called with the cancellation cause as soon
+	// as this Context cancellation begins, before Done() is closed and
+	// before children are waited on (unlike RegisterCleanup()). When
+	// cancellation is triggered through the CancelFunc of this Context or
+	// through CancelChildren(), fn is called synchronously by the canceling
+	// goroutine. When it is triggered in any other way (parent canceled,
+	// deadline exceeded, etc), fn is called as soon as it is noticed. If the
+	// Context is already canceled, fn is called immediately.
+	OnCancel(fn func(cause error))
+
+	// Defer registers fn to be run once this Context is done (compensation,
+	// audit tasks, etc). Deferred functions are tracked as children of this
+	// Context, so WaitForChildren() does not return before they ran, which
+	// also means it blocks until this Context is done while there are
+	// deferred functions registered.
+	Defer(fn func())
+
+	// Quiesce signals this Context and all contexts derived from it
+	// (including the ones derived later) to stop accepting new work while
+	// finishing the work in progress. It is the first phase of a two-phase
+	// shutdown, with cancellation reserved for the hard stop. It does not
+	// affect Done() or Err().
+	Quiesce()
 
-	pWg() *sync.WaitGroup
-	cWg() *sync.WaitGroup
+	// Quiescing returns a channel that is closed once Quiesce() is called on
+	// this Context or on any of its ancestors.
+	Quiescing() <-chan struct{}
+
+	// RequestExtension asks for the deadline of this Context to be extended
+	// by d. It returns true if the extension was granted by the policy of
+	// the parent (see SetExtensionPolicy()), in which case the deadline is
+	// now d later than before (or at the cap set with WithMaxTimeout() that
+	// applied when this Context was created, if that is earlier).
+	RequestExtension(d time.Duration) bool
+
+	// WaitStats returns statistics about how long children took to finish
+	// their work and how long WaitForChildren() calls blocked.
+	WaitStats() WaitStats
+
+	// Lifecycle returns the times of the main events in the life of this
+	// Context, so the latency of teardown phases can be measured.
+	Lifecycle() Lifecycle
+
+	// CancelChildren cancels all cancelable contexts derived from this
+	// Context (see WithCancel(), WithDeadline() and WithTimeout()), and so
+	// their subtrees, with the given cause. This includes the children of
+	// goroutines started with Go() and friends, Pool workers and
+	// supervisors (see Supervise()). This Context itself is not canceled and
+	// can be used to derive new children right away.
+	CancelChildren(cause error)
+
+	// Children returns the contexts directly derived from this Context that
+	// are not done yet. Only contexts that can be found from their parent
+	// are returned: cancelable and wait-enabled ones and those with such
+	// descendants (or all of them in debug mode, see SetDebug()). Children
+	// are tracked through weak references so derived contexts that became
+	// unreachable are never returned.
+	Children() []Context
+
+	// GoroutineCount returns the number of goroutines launched through the
+	// managed APIs (Go(), Pool, FanOut(), Pipeline, Supervise(), AdaptGroup(),
+	// etc) for this Context and all its descendants that are still running.
+	// Goroutines started with raw go statements are not counted (see
+	// WatchGoroutines()).
+	GoroutineCount() int
+
+	// Touch reports progress to the watchdogs of this Context and of all
+	// its ancestors (see WithWatchdog()). It does nothing if there are
+	// none.
+	Touch()
 }
 
+// ctxImpl is the Context implementation. The embedded context.Context
+// provides the standard library behavior while node holds the wait related
+// state. Different ctxImpls might share the same node (see Adopt()).
 type ctxImpl struct {
 	context.Context
+	*node
+}
+
+type node struct {
+	parent     Context
+	childrenWg waitGroup
+
+	// Context to report to when Finished() is called. If nil, the parent is
+	// used.
+	waitOn Context
+
+	// Set if the context does not report to any other context, even though
+	// it has a parent (see CloneDetachedWait()).
+	detached bool
+
+	// Number of EnableWait() calls on this context that still have no
+	// matching Finished() call.
+	waits atomic.Int64
+
+	// Set if the parent stopped waiting for this context (see
+	// WaitForChildrenOrAbandon()).
+	abandoned atomic.Bool
+
+	// Set once the context can be found from its parent (see track()).
+	tracked atomic.Bool
+
+	name string
+
+	// Policy for deadline extension requests from this context and from its
+	// children.
+	extension      *ExtensionPolicy
+	childExtension atomic.Pointer[ExtensionPolicy]
+
+	// Number of children Finished() calls (see WaitStats()).
+	childrenFinished atomic.Int64
 
-	parentWg   *sync.WaitGroup
-	childrenWg sync.WaitGroup
+	created time.Time
+
+	// Tracked contexts derived from this one (see track()). Created on
+	// demand.
+	kids atomic.Pointer[kids]
+
+	// Cancels the context with a cause. Only set for cancelable contexts.
+	cancel context.CancelCauseFunc
+
+	// Configuration inherited by derived contexts. Nil if everything has its
+	// default value.
+	inherited *inherited
+
+	// Set (in Unix nanoseconds) when the context is canceled through
+	// cancelWith() while wait timings are enabled. Zero otherwise.
+	canceledAt atomic.Int64
+
+	// Number of goroutines launched by the managed APIs that are running
+	// for this context or its descendants.
+	goroutines atomic.Int64
+
+	// State of features that most contexts do not use. Created on demand
+	// (see ext()).
+	extras atomic.Pointer[nodeExtras]
+}
+
+// nodeExtras holds the node state of rarely used features, so contexts that
+// do not use them stay small.
+type nodeExtras struct {
+	locals locals
+
+	store atomic.Pointer[Store]
+
+	firstErr atomic.Pointer[ChildError]
+
+	errsMu  sync.Mutex
+	errs    []error
+	misuses []error
+
+	stats stats
+
+	// Stack of the caller that created the context (only in debug mode).
+	stack []uintptr
+
+	onCancel onCancel
+
+	// Pending Defer() registrations.
+	defers defers
+
+	// Tags of this context registration and wait groups of tagged children
+	// (see EnableWaitTagged()).
+	tags   []string
+	tagged taggedWaits
+
+	quiesce quiesce
+
+	// Calls made with Do() under this context, if it is a root.
+	flights flightGroup
+
+	// Set when leak warnings are enabled and the context is wait-enabled.
+	leak atomic.Pointer[leakInfo]
+
+	// Capacity available to weighted children (see SetWaitCapacity()) and
+	// the weight this context holds on its parent, if any.
+	capacity atomic.Pointer[semaphore]
+	weight   *heldWeight
+
+	// Limit on the number of children (see SetMaxChildren()) and the slots
+	// this context holds on the limits of its parent (only set once a slot
+	// is acquired).
+	childLimit atomic.Pointer[childLimit]
+	heldSlots  atomic.Pointer[heldSlots]
+}
+
+// ext returns the extras of n, creating them if needed. Read only paths
+// should use n.extras.Load() instead and handle nil.
+func (n *node) ext() *nodeExtras {
+	if x := n.extras.Load(); x != nil {
+		return x
+	}
+
+	n.extras.CompareAndSwap(nil, &nodeExtras{})
+
+	return n.extras.Load()
+}
+
+// inherited holds the configuration inherited by derived contexts. It is
+// shared by all contexts with the same configuration, so it is never
+// modified once set (see setInherited()).
+type inherited struct {
+	// Set by WithClock().
+	clock Clock
+
+	// Set by WithMaxTimeout().
+	maxTimeout time.Duration
+
+	// Closest watchdog (see WithWatchdog()).
+	watchdog *watchdog
+
+	// Closest context to cancel when a managed child panics (see
+	// WithCancelOnPanic()).
+	panicCancel *ctxImpl
+}
+
+// defaults is the configuration of contexts without inherited configuration.
+var defaults inherited
+
+// config returns the inherited configuration of n.
+func (n *node) config() *inherited {
+	if n == nil || n.inherited == nil {
+		return &defaults
+	}
+
+	return n.inherited
+}
+
+// setInherited updates the inherited configuration of n (and so of contexts
+// derived from it afterwards) with fn. It must be called before n is
+// visible to other goroutines.
+func (n *node) setInherited(fn func(i *inherited)) {
+	i := *n.config()
+	fn(&i)
+	n.inherited = &i
+}
+
+// ctxNode is used to allocate a ctxImpl and its node with a single
+// allocation.
+type ctxNode struct {
+	ctxImpl
+	node
+}
+
+func newCtx(ctx context.Context, parent Context) *ctxImpl {
+	cn := &ctxNode{}
+
+	n := &cn.node
+	n.parent = parent
+	n.created = time.Now()
+
+	c := &cn.ctxImpl
+	c.Context = ctx
+	c.node = n
+
+	if stack := creationStack(); stack != nil {
+		n.ext().stack = stack
+	}
+
+	if parent != nil {
+		if p := nodeOf(parent); p != nil {
+			n.extension = p.childExtension.Load()
+			n.childExtension.Store(n.extension)
+			n.inherited = p.inherited
+		}
+
+		checkRequiredValues(c)
+
+		if debugMode.Load() {
+			// Make the whole tree visible for debugging.
+			c.track()
+		}
+	}
+
+	if expvarEnabled.Load() {
+		trackActive(c)
+	}
+
+	maybeSample(c)
+
+	return c
 }
 
 func (c *ctxImpl) Finished() {
-	if c.parentWg != nil {
+	if p := c.pNode(); p != nil {
 		// Only non-root contexts have parents.
-		c.parentWg.Done()
+		if !c.decrementWaits() {
+			if c.abandoned.Load() {
+				return
+			}
+
+			misuse(c, "Finished() called more times than EnableWait() on %v", c)
+			return
+		}
+
+		p.recordChildFinished(c, c.popEnabled())
+
+		pendingChildren.Add(-1)
+		if x := c.extras.Load(); x != nil {
+			if l := x.leak.Load(); l != nil {
+				l.pending.Add(-1)
+			}
+			c.releaseChildSlot()
+			if x.weight != nil {
+				x.weight.sem.release(x.weight.n)
+			}
+		}
+		c.finishTagged(p)
+		p.cWg().Done()
+	}
+}
+
+// decrementWaits decrements the number of pending Finished() calls if it is
+// positive. It returns false otherwise.
+func (n *node) decrementWaits() bool {
+	for {
+		waits := n.waits.Load()
+		if waits <= 0 {
+			return false
+		}
+
+		if n.waits.CompareAndSwap(waits, waits-1) {
+			return true
+		}
 	}
 }
 
 func (c *ctxImpl) WaitForChildren() {
+	if !checkWait(c, "WaitForChildren") {
+		return
+	}
+
+	c.waitForChildren()
+}
+
+// waitForChildren is like WaitForChildren() but it is never a misuse (see
+// CheckWaitNonRoot). It is used for waits done by this package itself.
+func (c *ctxImpl) waitForChildren() {
+	start := beginWait()
 	c.childrenWg.Wait()
+	c.recordWait(c, time.Since(start))
 }
 
-func (c *ctxImpl) context() context.Context {
-	return c.Context
+// WaitForChildrenProgress is like ctx.WaitForChildren() but calls report
+// (from the calling goroutine) with the number of children that already
+// finished and the total number of children every time a child finishes. If
+// ctx was not created by this package, it just calls ctx.WaitForChildren().
+func WaitForChildrenProgress(ctx Context, report func(finished, total int)) {
+	n := nodeOf(ctx)
+	if n == nil {
+		ctx.WaitForChildren()
+		return
+	}
+
+	if !checkWait(ctx, "WaitForChildrenProgress") {
+		return
+	}
+
+	start := beginWait()
+	n.childrenWg.waitProgress(report)
+	n.recordWait(ctx, time.Since(start))
+}
+
+// WaitForChildrenRespectingDeadline is like ctx.WaitForChildren() but stops
+// waiting and returns DeadlineExceeded if the deadline of ctx passes before
+// all children finished. Other forms of cancellation do not interrupt the
+// wait, but it still stops when the deadline passes after them. It returns
+// nil if all children finished. If ctx was not created by
+// this package, it just calls ctx.WaitForChildren().
+func WaitForChildrenRespectingDeadline(ctx Context) error {
+	n := nodeOf(ctx)
+	if n == nil {
+		ctx.WaitForChildren()
+		return nil
+	}
+
+	if !checkWait(ctx, "WaitForChildrenRespectingDeadline") {
+		return nil
+	}
+
+	start := beginWait()
+	defer func() {
+		n.recordWait(ctx, time.Since(start))
+	}()
+
+	if n.childrenWg.waitUntil(ctx.Done()) {
+		return nil
+	}
+
+	if ctx.Err() == DeadlineExceeded {
+		return DeadlineExceeded
+	}
+
+	// Canceled for some other reason. Only the deadline stops the wait.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		n.childrenWg.Wait()
+		return nil
+	}
+
+	clock := n.getClock()
+
+	expired := make(chan struct{})
+	stop := clock.AfterFunc(deadline.Sub(clock.Now()), func() { close(expired) })
+	defer stop()
+
+	if n.childrenWg.waitUntil(expired) {
+		return nil
+	}
+
+	return DeadlineExceeded
+}
+
+func (n *node) CancelChildren(cause error) {
+	for _, kid := range n.liveKids() {
+		if kid.cancel != nil {
+			kid.cancelWith(cause)
+			continue
+		}
+
+		// Not cancelable by itself (WithName(), etc). Look for cancelable
+		// contexts below it.
+		kid.CancelChildren(cause)
+	}
+}
+
+func (n *node) Children() []Context {
+	var children []Context
+	for _, kid := range n.liveKids() {
+		if kid.Err() == nil {
+			children = append(children, kid)
+		}
+	}
+
+	return children
+}
+
+func (n *node) AfterChildrenFinished(fn func()) {
+	n.childrenWg.afterDone(fn)
+}
+
+func (n *node) Parent() Context {
+	return n.parent
+}
+
+func (c *ctxImpl) Root() Context {
+	var root Context = c
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+
+	return root
+}
+
+// pNode returns the node to report to when Finished() is called or nil if
+// there is none.
+func (n *node) pNode() *node {
+	if n.detached {
+		return nil
+	}
+
+	target := n.waitOn
+	if target == nil {
+		target = n.parent
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	return nodeOf(target)
+}
+
+func (n *node) pWg() *waitGroup {
+	if p := n.pNode(); p != nil {
+		return p.cWg()
+	}
+
+	return nil
 }
 
-func (c *ctxImpl) pWg() *sync.WaitGroup {
-	return c.parentWg
+func (n *node) cWg() *waitGroup {
+	return &n.childrenWg
 }
 
-func (c *ctxImpl) cWg() *sync.WaitGroup {
-	return &c.childrenWg
+// String returns a description of the context in the same format used by the
+// standard library (for example, "context.Background.WithCancel"), followed
+// by the name of the context (if any) and its pending wait counts.
+func (c *ctxImpl) String() string {
+	var b strings.Builder
+
+	b.WriteString(contextName(c.Context))
+	if c.name != "" {
+		fmt.Fprintf(&b, ".WithName(%q)", c.name)
+	}
+
+	children, waits := c.childrenWg.count(), c.waits.Load()
+	if children != 0 || waits != 0 {
+		fmt.Fprintf(&b, "[children=%d waits=%d]", children, waits)
+	}
+
+	return b.String()
+}
+
+// GoString returns a detailed description of the context, including all its
+// wait related state. It is used when formatting with %#v.
+func (c *ctxImpl) GoString() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "context.Context{std: %q", contextName(c.Context))
+	if c.name != "" {
+		fmt.Fprintf(&b, ", name: %q", c.name)
+	}
+	if deadline, ok := c.Deadline(); ok {
+		fmt.Fprintf(&b, ", deadline: %q", deadline.Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(&b, ", children: %d, waits: %d, root: %t}",
+		c.childrenWg.count(), c.waits.Load(), c.parent == nil)
+
+	return b.String()
 }
 
 func (c *ctxImpl) Err() error {
@@ -97,49 +662,149 @@ func (c *ctxImpl) Err() error {
 	return c.Context.Err()
 }
 
-func Background() Context {
-	return &ctxImpl{
-		context.Background(),
-		nil,
-		sync.WaitGroup{},
+// nodeKey is the key used to rediscover the Context associated with a
+// standard library context derived from it.
+type nodeKey struct{}
+
+func (c *ctxImpl) Value(key any) any {
+	if key == (nodeKey{}) {
+		return c
 	}
+
+	return c.Context.Value(key)
 }
 
-func TODO() Context {
-	return &ctxImpl{
-		context.TODO(),
-		nil,
-		sync.WaitGroup{},
+// lookup returns the ctxImpl associated with the given context or nil if
+// there is none.
+func lookup(ctx context.Context) *ctxImpl {
+	if c, ok := ctx.(*ctxImpl); ok {
+		return c
 	}
+
+	c, _ := ctx.Value(nodeKey{}).(*ctxImpl)
+
+	return c
+}
+
+// nodeOf returns the node associated with the given context or nil if there
+// is none.
+func nodeOf(ctx context.Context) *node {
+	if c := lookup(ctx); c != nil {
+		return c.node
+	}
+
+	return nil
+}
+
+// stdContext returns the standard library context to derive new contexts
+// from.
+func stdContext(ctx Context) context.Context {
+	if c, ok := ctx.(*ctxImpl); ok {
+		// Skip a level of indirection.
+		return c.Context
+	}
+
+	return ctx
+}
+
+func (c *ctxImpl) Unwrap() context.Context {
+	return c.Context
+}
+
+// contextName returns the description of the given standard library context.
+func contextName(c context.Context) string {
+	if s, ok := c.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return reflect.TypeOf(c).String()
+}
+
+func Background() Context {
+	return newCtx(context.Background(), nil)
+}
+
+func TODO() Context {
+	return newCtx(context.TODO(), nil)
 }
 
 type CancelFunc context.CancelFunc
 
 func WithCancel(parent Context) (Context, CancelFunc) {
-	ctx, c := context.WithCancel(parent.context())
-	return &ctxImpl{
-		ctx,
-		parent.cWg(),
-		sync.WaitGroup{},
-	}, CancelFunc(c)
+	ctx, cancel := context.WithCancelCause(stdContext(parent))
+
+	c := newCtx(ctx, parent)
+	c.cancel = cancel
+	c.track()
+
+	derived(parent, c, KindCancel)
+
+	return c, func() { c.cancelWith(nil) }
+}
+
+// withCancelName is like WithName(WithCancel(parent), name) but creates a
+// single context. It is used for the children of managed APIs, so they can be
+// canceled with CancelChildren().
+func withCancelName(parent Context, name string) (Context, CancelFunc) {
+	ctx, cancel := context.WithCancelCause(stdContext(parent))
+
+	c := newCtx(ctx, parent)
+	c.cancel = cancel
+	c.track()
+	c.name = name
+
+	derived(parent, c, KindCancel)
+
+	return c, func() { c.cancelWith(nil) }
 }
 
 func WithDeadline(parent Context, deadline time.Time) (Context, CancelFunc) {
-	ctx, c := context.WithDeadline(parent.context(), deadline)
-	return &ctxImpl{
-		ctx,
-		parent.cWg(),
-		sync.WaitGroup{},
-	}, CancelFunc(c)
+	p := nodeOf(parent)
+
+	var max time.Time
+	if maxTimeout := p.config().maxTimeout; maxTimeout > 0 {
+		max = p.getClock().Now().Add(maxTimeout)
+		if deadline.After(max) {
+			deadline = max
+		}
+	}
+
+	// The deadline layer is topped with a cancelable one, so the context can
+	// be canceled with a cause (see CancelChildren()).
+	extendable := p != nil && p.childExtension.Load() != nil
+	std, stop := withDeadline(stdContext(parent), deadline, max, p.getClock(), extendable)
+
+	ctx, cancel := context.WithCancelCause(std)
+	if e, ok := std.(*extendableCtx); ok {
+		e.ctx = ctx
+	}
+
+	c := newCtx(ctx, parent)
+	c.cancel = cancel
+	c.track()
+
+	derived(parent, c, KindDeadline)
+
+	return c, func() {
+		c.cancelWith(nil)
+		stop()
+	}
 }
 
 func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
-	ctx, c := context.WithTimeout(parent.context(), timeout)
-	return &ctxImpl{
-		ctx,
-		parent.cWg(),
-		sync.WaitGroup{},
-	}, CancelFunc(c)
+	return WithDeadline(parent, nodeOf(parent).getClock().Now().Add(timeout))
+}
+
+// WithName returns a copy of parent with the given name attached to it. The
+// name has no effect on the context behavior and is only used to identify it
+// when printing it or when debugging.
+func WithName(parent Context, name string) Context {
+	c := newCtx(stdContext(parent), parent)
+	c.name = name
+
+	derived(parent, c, KindName)
+
+	return c
 }
 
 // EnableWait enables waiting on this context completion. When the work
@@ -147,11 +812,183 @@ func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
 // number of times that EnableWait() is called), any caller waiting on the
 // parent context will unblock.
 func EnableWait(ctx Context) Context {
-	if ctx.pWg() == nil {
-		panic("tried to call EnableWait() on a root context")
+	c, _ := enableWait(ctx, 1, "EnableWait", false)
+
+	return c
+}
+
+// EnableWaitN is like calling EnableWait() count times, but the registrations
+// are done atomically. This is useful to register a whole batch of work up
+// front, before launching it.
+func EnableWaitN(ctx Context, count int) Context {
+	if count <= 0 {
+		panic("tried to call EnableWaitN() with a non-positive count")
+	}
+
+	c, _ := enableWait(ctx, count, "EnableWaitN", false)
+
+	return c
+}
+
+// FinishedN is like calling ctx.Finished() count times. It is the counterpart
+// of EnableWaitN().
+func FinishedN(ctx Context, count int) {
+	for i := 0; i < count; i++ {
+		ctx.Finished()
+	}
+}
+
+// enableWait registers count units of work for ctx with its parent. If try is
+// true, an error is returned instead of blocking or reporting a misuse when
+// the parent limit on children is reached (see SetMaxChildren()). Otherwise,
+// failures are reported as misuses and also returned, so callers can roll
+// back.
+func enableWait(ctx Context, count int, caller string, try bool) (Context, error) {
+	return enableWaitFor(ctx, ctx, count, caller, try)
+}
+
+// enableWaitFor is like enableWait() but misuses are reported on report
+// (the Context the caller passed in, for internally derived contexts).
+func enableWaitFor(report, ctx Context, count int, caller string, try bool) (Context, error) {
+	n := nodeOf(ctx)
+	if n == nil || n.pWg() == nil {
+		return ctx, misuse(ctx, "%s() called on root context %v", caller, ctx)
+	}
+
+	if checkEnabled(CheckReuseAfterDone) && ctx.Err() != nil {
+		return ctx, misuse(report, "%s() called on %v that is already done", caller, report)
+	}
+
+	if err := n.acquireChildSlots(ctx, count, try); err != nil {
+		if try {
+			return ctx, err
+		}
+
+		return ctx, misuse(report, "%s() called on %v: %v", caller, report, err)
+	}
+
+	n.pWg().Add(count)
+	n.waits.Add(int64(count))
+	pendingChildren.Add(int64(count))
+	n.pushEnabled(count)
+
+	if c := lookup(ctx); !c.tracked.Load() {
+		c.track()
+	}
+
+	if leakWarnings.Load() {
+		n.trackLeak(count)
 	}
 
-	ctx.pWg().Add(1)
+	if n.waitOn != nil {
+		derived(n.waitOn, ctx, KindWait)
+	} else {
+		derived(n.parent, ctx, KindWait)
+	}
+
+	return ctx, nil
+}
+
+// EnableWaitAutoFinish is like EnableWait but Finished() is automatically
+// called when ctx is done. This is useful for work whose lifetime is exactly
+// the lifetime of the context, so there is no need to explicitly call
+// Finished().
+func EnableWaitAutoFinish(ctx Context) Context {
+	EnableWait(ctx)
+	context.AfterFunc(ctx, ctx.Finished)
 
 	return ctx
 }
+
+// EnableWaitOn is like EnableWait but registers the work associated with ctx
+// with the given ancestor instead of with the immediate parent. This allows
+// callers waiting on any ancestor to also wait on deeper descendants without
+// intermediate contexts having to proxy Finished() calls upward.
+//
+// It returns a copy of ctx that must be used to report completion (by calling
+// Finished() on it). If ancestor is not an ancestor of ctx, a misuse is
+// reported (see SetMisusePolicy()) and nothing is registered.
+func EnableWaitOn(ancestor Context, ctx Context) Context {
+	if !isAncestor(ancestor, ctx) {
+		misuse(ctx, "EnableWaitOn() called with %v that is not an ancestor of %v", ancestor, ctx)
+		return ctx
+	}
+
+	c := newCtx(stdContext(ctx), ctx)
+	c.waitOn = ancestor
+	if n := nodeOf(ctx); n != nil {
+		c.name = n.name
+	}
+
+	return EnableWait(c)
+}
+
+func isAncestor(ancestor Context, ctx Context) bool {
+	for p := ctx.Parent(); p != nil; p = p.Parent() {
+		if p == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Adopt returns a Context for the given standard library context. If ctx was
+// derived (directly or through any number of standard library layers) from a
+// Context, the returned Context shares its wait state so, for example, calling
+// Finished() on it reports to the same parent. Otherwise, a new root Context
+// wrapping ctx is returned.
+//
+// The returned Context behaves exactly like ctx (same values, deadline and
+// cancellation).
+func Adopt(ctx context.Context) Context {
+	if c, ok := ctx.(Context); ok {
+		return c
+	}
+
+	if c := lookup(ctx); c != nil {
+		return &ctxImpl{
+			Context: ctx,
+			node:    c.node,
+		}
+	}
+
+	return newCtx(ctx, nil)
+}
+
+// CloneDetachedWait returns a copy of ctx that shares its cancellation,
+// deadline and values (except the ones with the ValueNonInheritable policy,
+// see SetValuePolicy()) but has independent wait state: it starts with no
+// children and it is a wait root (it does not report to any other context).
+// This allows sub-frameworks to run their own wait rounds without interfering
+// with the wait accounting of the caller.
+func CloneDetachedWait(ctx Context) Context {
+	c := newCtx(stripValues(stdContext(ctx)), ctx)
+	c.detached = true
+	if n := nodeOf(ctx); n != nil {
+		c.name = n.name
+	}
+
+	derived(ctx, c, KindOther)
+
+	return c
+}
+
+// IsWaitEnabled returns true if EnableWait() was called on the given context
+// and there are still pending Finished() calls for it. Code that is handed a
+// context it did not create can use this to check if it is expected to call
+// Finished() on it.
+func IsWaitEnabled(ctx Context) bool {
+	return WaitCount(ctx) > 0
+}
+
+// WaitCount returns the number of times Finished() still needs to be called
+// on the given context for any callers waiting on its parent to unblock.
+func WaitCount(ctx Context) int {
+	n := nodeOf(ctx)
+	if n == nil {
+		return 0
+	}
+
+	return int(n.waits.Load())
+}
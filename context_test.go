@@ -1,6 +1,9 @@
 package context
 
 import (
+	stdcontext "context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -58,3 +61,521 @@ func TestWait_MultipleChildren(t *testing.T) {
 		t.Errorf("Expected value to be 3. Got %d.", value)
 	}
 }
+
+func TestWaitForChildrenRespectingDeadline(t *testing.T) {
+	parent, cancel := WithTimeout(Background(), 5*time.Millisecond)
+	defer cancel()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	stuck := EnableWait(ctx)
+
+	if err := WaitForChildrenRespectingDeadline(parent); err != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+
+	stuck.Finished()
+
+	if err := WaitForChildrenRespectingDeadline(parent); err != nil {
+		t.Errorf("Expected no error. Got %v.", err)
+	}
+}
+
+func TestWaitForChildrenRespectingDeadline_Canceled(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+
+	ctx, cancelChild := WithCancel(parent)
+	defer cancelChild()
+
+	go func(ctx Context) {
+		cancel()
+		time.Sleep(1 * time.Millisecond)
+		ctx.Finished()
+	}(EnableWait(ctx))
+
+	if err := WaitForChildrenRespectingDeadline(parent); err != nil {
+		t.Errorf("Expected no error. Got %v.", err)
+	}
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestWaitForChildrenRespectingDeadline_CanceledDeadline(t *testing.T) {
+	parent, cancel := WithTimeout(Background(), 10*time.Millisecond)
+	defer cancel()
+
+	child := EnableWait(WithName(parent, "stuck"))
+	defer child.Finished()
+
+	// Canceled before the deadline. The wait must still end at the deadline.
+	cancel()
+
+	if err := WaitForChildrenRespectingDeadline(parent); err != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+}
+
+func TestWithTimeout_StdChild(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 1*time.Millisecond)
+	defer cancel()
+
+	std, cancelStd := stdcontext.WithCancel(ctx)
+	defer cancelStd()
+
+	<-std.Done()
+
+	if err := std.Err(); err != stdcontext.DeadlineExceeded {
+		t.Errorf("Expected standard library child error to be %v. Got %v.", stdcontext.DeadlineExceeded, err)
+	}
+}
+
+func TestCancelChildren(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	errWave := fmt.Errorf("wave aborted")
+
+	child, cancelChild := WithCancel(parent)
+	defer cancelChild()
+
+	named := WithName(parent, "named")
+	grandChild, cancelGrandChild := WithTimeout(named, time.Hour)
+	defer cancelGrandChild()
+
+	parent.CancelChildren(errWave)
+
+	for _, ctx := range []Context{child, grandChild} {
+		if ctx.Err() != Canceled {
+			t.Errorf("Expected %v to be canceled. Got %v.", ctx, ctx.Err())
+		}
+		if cause := stdcontext.Cause(ctx); cause != errWave {
+			t.Errorf("Expected cause to be %v. Got %v.", errWave, cause)
+		}
+	}
+
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+
+	replacement, cancelReplacement := WithCancel(parent)
+	defer cancelReplacement()
+
+	if replacement.Err() != nil {
+		t.Errorf("Expected replacement not to be canceled. Got %v.", replacement.Err())
+	}
+}
+
+func TestCancelChildren_Go(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	errWave := fmt.Errorf("wave aborted")
+
+	causes := make(chan error, 1)
+	Go(parent, func(ctx Context) error {
+		<-ctx.Done()
+		causes <- stdcontext.Cause(ctx)
+		return nil
+	})
+
+	parent.CancelChildren(errWave)
+
+	select {
+	case cause := <-causes:
+		if cause != errWave {
+			t.Errorf("Expected cause to be %v. Got %v.", errWave, cause)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timeout waiting for Go() child to be canceled.")
+	}
+
+	parent.WaitForChildren()
+
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+}
+
+func TestChildren(t *testing.T) {
+	parent := Background()
+
+	child1, cancel1 := WithCancel(parent)
+	defer cancel1()
+
+	child2, cancel2 := WithTimeout(parent, time.Hour)
+	cancel2()
+
+	children := parent.Children()
+	if len(children) != 1 {
+		t.Fatalf("Expected 1 child. Got %d.", len(children))
+	}
+	if children[0] != child1 {
+		t.Errorf("Expected child to be %v. Got %v.", child1, children[0])
+	}
+	if _, ok := children[0].Deadline(); ok {
+		t.Errorf("Expected child to have no deadline.")
+	}
+
+	if n := len(child2.Children()); n != 0 {
+		t.Errorf("Expected no children. Got %d.", n)
+	}
+}
+
+func TestString(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	if s := fmt.Sprint(ctx); s != "context.Background.WithCancel" {
+		t.Errorf("Unexpected string %q.", s)
+	}
+
+	named := EnableWait(WithName(ctx, "worker"))
+	defer named.Finished()
+
+	expected := `context.Background.WithCancel.WithName("worker")[children=0 waits=1]`
+	if s := fmt.Sprint(named); s != expected {
+		t.Errorf("Expected %q. Got %q.", expected, s)
+	}
+
+	expected = "context.Background.WithCancel[children=1 waits=0]"
+	if s := fmt.Sprint(ctx); s != expected {
+		t.Errorf("Expected %q. Got %q.", expected, s)
+	}
+
+	timeout, cancelTimeout := WithTimeout(parent, time.Hour)
+	defer cancelTimeout()
+
+	if s := fmt.Sprint(timeout); !strings.HasPrefix(s, "context.Background.WithDeadline(") {
+		t.Errorf("Unexpected string %q.", s)
+	}
+}
+
+func TestGoString(t *testing.T) {
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx, cancel := WithDeadline(Background(), deadline)
+	defer cancel()
+
+	s := fmt.Sprintf("%#v", WithName(ctx, "worker"))
+	for _, expected := range []string{
+		`name: "worker"`,
+		`deadline: "2030-01-01T00:00:00Z"`,
+		"children: 0, waits: 0, root: false",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected %q to contain %q.", s, expected)
+		}
+	}
+}
+
+func TestWaitCount(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	if IsWaitEnabled(ctx) {
+		t.Errorf("Expected wait to be disabled.")
+	}
+
+	EnableWait(ctx)
+	EnableWait(ctx)
+
+	if !IsWaitEnabled(ctx) {
+		t.Errorf("Expected wait to be enabled.")
+	}
+	if n := WaitCount(ctx); n != 2 {
+		t.Errorf("Expected wait count to be 2. Got %d.", n)
+	}
+
+	ctx.Finished()
+	ctx.Finished()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+	if IsWaitEnabled(Background()) {
+		t.Errorf("Expected wait to be disabled for root context.")
+	}
+}
+
+func TestParentAndRoot(t *testing.T) {
+	root := Background()
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	child := WithName(ctx, "child")
+
+	if root.Parent() != nil {
+		t.Errorf("Expected root parent to be nil.")
+	}
+	if root.Root() != root {
+		t.Errorf("Expected root to be its own root.")
+	}
+	if child.Parent() != ctx {
+		t.Errorf("Expected child parent to be %v. Got %v.", ctx, child.Parent())
+	}
+	if child.Root() != root {
+		t.Errorf("Expected child root to be %v. Got %v.", root, child.Root())
+	}
+}
+
+func TestEnableWaitOn(t *testing.T) {
+	root := Background()
+
+	mid, cancel := WithCancel(root)
+	defer cancel()
+
+	ctx, cancel := WithCancel(mid)
+	defer cancel()
+
+	value := 0
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		value = 1
+		ctx.Finished()
+	}(EnableWaitOn(root, ctx))
+
+	root.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+}
+
+func TestEnableWaitOn_NotAncestor(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected EnableWaitOn() to panic.")
+		}
+	}()
+
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	EnableWaitOn(Background(), ctx)
+}
+
+func TestAdopt(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	type key struct{}
+
+	value := 0
+
+	go func(ctx stdcontext.Context) {
+		time.Sleep(1 * time.Millisecond)
+		value = 1
+
+		adopted := Adopt(ctx)
+		if adopted.Value(key{}) != "value" {
+			t.Errorf("Expected adopted context to keep values.")
+		}
+		adopted.Finished()
+	}(stdcontext.WithValue(EnableWait(ctx), key{}, "value"))
+
+	parent.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+}
+
+func TestAdopt_Standard(t *testing.T) {
+	ctx := Adopt(stdcontext.Background())
+
+	if ctx.Parent() != nil {
+		t.Errorf("Expected adopted standard context to be a root.")
+	}
+	if Adopt(ctx) != ctx {
+		t.Errorf("Expected adopting a Context to return it.")
+	}
+}
+
+type mockCtx struct {
+	stdcontext.Context
+}
+
+func (mockCtx) Finished()                           {}
+func (mockCtx) FinishedErr(error)                   {}
+func (mockCtx) ChildrenErr() error                  { return nil }
+func (mockCtx) WaitForChildren()                    {}
+func (mockCtx) GoroutineCount() int                 { return 0 }
+func (mockCtx) CancelChildren(error)                {}
+func (mockCtx) Children() []Context                 { return nil }
+func (mockCtx) RegisterCleanup(func(error))         {}
+func (mockCtx) OnCancel(func(error))                {}
+func (mockCtx) Touch()                              {}
+func (mockCtx) Defer(func())                        {}
+func (mockCtx) Quiesce()                            {}
+func (mockCtx) Quiescing() <-chan struct{}          { return nil }
+func (mockCtx) FirstError() error                   { return nil }
+func (mockCtx) Lifecycle() Lifecycle                { return Lifecycle{} }
+func (mockCtx) Parent() Context                     { return nil }
+func (m mockCtx) Root() Context                     { return m }
+func (m mockCtx) Unwrap() stdcontext.Context        { return m.Context }
+func (mockCtx) AfterChildrenFinished(func())        {}
+func (mockCtx) NewBarrier(int) *Barrier             { return nil }
+func (mockCtx) Store() *Store                       { return nil }
+func (mockCtx) SetLocal(_, _ any, _ func())         {}
+func (mockCtx) Local(any) (any, bool)               { return nil, false }
+func (mockCtx) RequestExtension(time.Duration) bool { return false }
+func (mockCtx) WaitStats() WaitStats                { return WaitStats{} }
+
+func TestExternalImplementation(t *testing.T) {
+	var parent Context = mockCtx{stdcontext.Background()}
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	if ctx.Parent() != parent {
+		t.Errorf("Expected parent to be the mock context.")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected EnableWait() to panic.")
+		}
+	}()
+
+	EnableWait(ctx)
+}
+
+func TestExternalImplementation_Wrapper(t *testing.T) {
+	type wrapper struct {
+		Context
+	}
+
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	value := 0
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		value = 1
+		ctx.Finished()
+	}(EnableWait(wrapper{ctx}))
+
+	parent.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	if Background().Unwrap() != stdcontext.Background() {
+		t.Errorf("Expected unwrapped Background() to be the standard library one.")
+	}
+	if TODO().Unwrap() != stdcontext.TODO() {
+		t.Errorf("Expected unwrapped TODO() to be the standard library one.")
+	}
+
+	ctx, cancel := WithCancel(Background())
+	cancel()
+
+	select {
+	case <-ctx.Unwrap().Done():
+	default:
+		t.Errorf("Expected unwrapped context to be canceled.")
+	}
+}
+
+func TestEnableWaitAutoFinish(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	EnableWaitAutoFinish(ctx)
+
+	if n := WaitCount(ctx); n != 1 {
+		t.Errorf("Expected wait count to be 1. Got %d.", n)
+	}
+
+	go func() {
+		time.Sleep(1 * time.Millisecond)
+		cancel()
+	}()
+
+	parent.WaitForChildren()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestEnableWaitN(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	const numWorkers = 5
+
+	EnableWaitN(ctx, numWorkers)
+
+	if n := WaitCount(ctx); n != numWorkers {
+		t.Errorf("Expected wait count to be %d. Got %d.", numWorkers, n)
+	}
+
+	for i := 0; i < numWorkers-2; i++ {
+		go ctx.Finished()
+	}
+	go FinishedN(ctx, 2)
+
+	parent.WaitForChildren()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestCloneDetachedWait(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	EnableWait(ctx)
+	defer ctx.Finished()
+
+	clone := CloneDetachedWait(ctx)
+
+	value := 0
+	go func(ctx Context) {
+		value = 1
+		ctx.Finished()
+	}(EnableWait(WithName(clone, "sub")))
+
+	// Only waits on the clone children.
+	clone.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+
+	cancel()
+
+	select {
+	case <-clone.Done():
+	default:
+		t.Errorf("Expected clone to be canceled with the original context.")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected EnableWait() on the clone to panic.")
+		}
+	}()
+
+	EnableWait(clone)
+}
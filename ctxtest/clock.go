@@ -0,0 +1,108 @@
+// Package ctxtest provides utilities for testing code that uses contexts.
+package ctxtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a manually advanced clock that implements context.Clock. Use it
+// with context.WithClock() to deterministically fire the deadlines of derived
+// contexts by calling Advance(), instead of sleeping.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	when time.Time
+	f    func()
+}
+
+// NewClock returns a Clock with the given current time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now: now,
+	}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// AfterFunc arranges for f to be called when the clock is advanced by at
+// least d. If d is not positive, f is called right away.
+func (c *Clock) AfterFunc(d time.Duration, f func()) func() bool {
+	if d <= 0 {
+		f()
+		return func() bool { return false }
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{c.now.Add(d), f}
+	c.timers = append(c.timers, t)
+
+	return func() bool {
+		return c.stop(t)
+	}
+}
+
+// Advance moves the clock forward by d and synchronously calls the functions
+// of all timers that expired, in expiration order. When it returns, all
+// contexts with deadlines up to the new current time are done.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+
+	c.now = c.now.Add(d)
+
+	var expired, pending []*timer
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			expired = append(expired, t)
+		}
+	}
+	c.timers = pending
+
+	c.mu.Unlock()
+
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].when.Before(expired[j].when)
+	})
+
+	for _, t := range expired {
+		t.f()
+	}
+}
+
+// Pending returns the number of timers that did not expire and were not
+// stopped yet.
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+func (c *Clock) stop(t *timer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package ctxtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+
+	var fired []int
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	c.AfterFunc(1*time.Second, func() { fired = append(fired, 1) })
+	stop := c.AfterFunc(3*time.Second, func() { fired = append(fired, 3) })
+
+	c.Advance(500 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Errorf("Expected no timers to fire. Got %v.", fired)
+	}
+
+	if !stop() {
+		t.Errorf("Expected stop to succeed.")
+	}
+
+	c.Advance(5 * time.Second)
+	if len(fired) != 2 || fired[0] != 1 || fired[1] != 2 {
+		t.Errorf("Expected timers 1 and 2 to fire in order. Got %v.", fired)
+	}
+
+	if now := c.Now(); !now.Equal(start.Add(5500 * time.Millisecond)) {
+		t.Errorf("Expected now to be %v. Got %v.", start.Add(5500*time.Millisecond), now)
+	}
+	if n := c.Pending(); n != 0 {
+		t.Errorf("Expected no pending timers. Got %d.", n)
+	}
+	if stop() {
+		t.Errorf("Expected stop to fail for stopped timer.")
+	}
+}
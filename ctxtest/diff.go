@@ -0,0 +1,91 @@
+package ctxtest
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brunoga/context"
+)
+
+// Diff reports the differences between a and b in deadlines, values (for
+// keys registered with context.RegisterDebugKey()) and wait state (pending
+// children and pending Finished() calls), one per line. It returns an empty
+// string if there are none. The state is obtained with context.Describe(), so
+// contexts reached through other layers (standard library contexts derived
+// from a context.Context, wrappers, etc) are fully compared.
+//
+// It is meant for tests asserting that code derived a context correctly:
+//
+//	if diff := ctxtest.Diff(want, got); diff != "" {
+//		t.Errorf("Unexpected context (-want +got):\n%s", diff)
+//	}
+func Diff(a, b stdcontext.Context) string {
+	da, db := context.Describe(a), context.Describe(b)
+
+	var lines []string
+	add := func(what string, va, vb any) {
+		lines = append(lines, fmt.Sprintf("%s: -%v +%v", what, va, vb))
+	}
+
+	if !sameDeadline(da.Deadline, db.Deadline) {
+		add("deadline", formatDeadline(da.Deadline), formatDeadline(db.Deadline))
+	}
+
+	names := make(map[string]bool)
+	for name := range da.Values {
+		names[name] = true
+	}
+	for name := range db.Values {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		va, okA := da.Values[name]
+		vb, okB := db.Values[name]
+		if va != vb || okA != okB {
+			add(fmt.Sprintf("value %q", name), formatValue(va, okA), formatValue(vb, okB))
+		}
+	}
+
+	if da.PendingChildren != db.PendingChildren {
+		add("pending children", da.PendingChildren, db.PendingChildren)
+	}
+	if da.PendingFinished != db.PendingFinished {
+		add("pending Finished() calls", da.PendingFinished, db.PendingFinished)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func sameDeadline(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func formatDeadline(deadline *time.Time) string {
+	if deadline == nil {
+		return "none"
+	}
+
+	return deadline.String()
+}
+
+func formatValue(value string, ok bool) string {
+	if !ok {
+		return "unset"
+	}
+
+	return fmt.Sprintf("%q", value)
+}
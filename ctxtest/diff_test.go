@@ -0,0 +1,57 @@
+package ctxtest
+
+import (
+	stdcontext "context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunoga/context"
+)
+
+type diffKey string
+
+func init() {
+	context.RegisterDebugKey("user", diffKey("user"))
+	context.RegisterDebugKey("request", diffKey("request"))
+}
+
+func TestDiff(t *testing.T) {
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	parent := context.Background()
+
+	a, cancelA := context.WithDeadline(parent, deadline)
+	defer cancelA()
+	b, cancelB := context.WithDeadline(parent, deadline)
+	defer cancelB()
+
+	if diff := Diff(a, b); diff != "" {
+		t.Errorf("Expected no differences. Got %q.", diff)
+	}
+
+	if diff := Diff(a, parent); !strings.HasPrefix(diff, "deadline:") {
+		t.Errorf("Expected deadline difference. Got %q.", diff)
+	}
+}
+
+func TestDiff_Derived(t *testing.T) {
+	parent := context.WithValues(context.Background(), diffKey("user"), "alice")
+
+	want := context.WithName(parent, "want")
+	context.EnableWait(context.WithName(want, "child"))
+
+	// Reached through a standard library layer.
+	got := stdcontext.WithValue(context.WithValues(parent,
+		diffKey("user"), "bob", diffKey("request"), "1"), diffKey("other"), "x")
+
+	expected := strings.Join([]string{
+		`value "request": -unset +"1"`,
+		`value "user": -"alice" +"bob"`,
+		`pending children: -1 +0`,
+	}, "\n")
+
+	if diff := Diff(want, got); diff != expected {
+		t.Errorf("Expected diff %q. Got %q.", expected, diff)
+	}
+}
@@ -0,0 +1,122 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// WithJitteredTimeout is like WithTimeout() but the effective timeout is
+// randomly picked in the [base*(1-jitterFraction), base*(1+jitterFraction)]
+// interval. This prevents timeout storms when many contexts are created at
+// the same time with the same timeout. jitterFraction must be between 0 and
+// 1.
+func WithJitteredTimeout(parent Context, base time.Duration, jitterFraction float64) (Context, CancelFunc) {
+	if jitterFraction < 0 || jitterFraction > 1 {
+		panic("jitter fraction must be between 0 and 1")
+	}
+
+	jitter := (2*rand.Float64() - 1) * jitterFraction
+
+	return WithTimeout(parent, base+time.Duration(float64(base)*jitter))
+}
+
+// WithMaxTimeout returns a copy of parent that caps the deadline of any
+// context later derived from it (directly or indirectly) with WithDeadline()
+// or WithTimeout() to max after the time it is derived. This allows enforcing
+// an upper bound on the duration of work regardless of what downstream code
+// requests. If a cap is already in place, the smallest one applies.
+func WithMaxTimeout(parent Context, max time.Duration) Context {
+	c := newCtx(stdContext(parent), parent)
+	c.setInherited(func(i *inherited) {
+		if i.maxTimeout == 0 || max < i.maxTimeout {
+			i.maxTimeout = max
+		}
+	})
+
+	derived(parent, c, KindOther)
+
+	return c
+}
+
+// EarliestDeadline returns the earliest deadline among all the given
+// contexts. The boolean result is false if none of them has a deadline.
+func EarliestDeadline(ctxs ...Context) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+
+	for _, ctx := range ctxs {
+		deadline, ok := ctx.Deadline()
+		if ok && (!found || deadline.Before(earliest)) {
+			earliest = deadline
+			found = true
+		}
+	}
+
+	return earliest, found
+}
+
+// RemainingBudget returns the time left until the deadline of ctx or 0 if it
+// already passed. If ctx has no deadline, the budget is unlimited and the
+// largest possible time.Duration is returned, so the result can always be
+// used in budget computations (splitting it between sub-tasks, etc).
+func RemainingBudget(ctx Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return math.MaxInt64
+	}
+
+	return max(deadline.Sub(nodeOf(ctx).getClock().Now()), 0)
+}
+
+// delayedCtx is the standard library side of a context created with
+// WithCancelDelay().
+type delayedCtx struct {
+	context.Context
+
+	parent context.Context
+	grace  time.Duration
+}
+
+func (d *delayedCtx) Deadline() (time.Time, bool) {
+	deadline, ok := d.parent.Deadline()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return deadline.Add(d.grace), true
+}
+
+func (d *delayedCtx) String() string {
+	return fmt.Sprintf("%s.WithCancelDelay(%s)", contextName(d.parent), d.grace)
+}
+
+// WithCancelDelay returns a copy of parent that is only canceled grace after
+// parent is (with the same cause), giving in-flight work a bounded window to
+// complete before observing the cancellation. Calling the returned CancelFunc
+// cancels it immediately.
+func WithCancelDelay(parent Context, grace time.Duration) (Context, CancelFunc) {
+	std := stdContext(parent)
+
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(std))
+
+	c := newCtx(&delayedCtx{ctx, std, grace}, parent)
+	c.cancel = cancel
+	c.track()
+
+	clock := c.getClock()
+	stop := context.AfterFunc(std, func() {
+		clock.AfterFunc(grace, func() {
+			cancel(context.Cause(std))
+		})
+	})
+	context.AfterFunc(ctx, func() {
+		stop()
+	})
+
+	derived(parent, c, KindCancel)
+
+	return c, func() { c.cancelWith(nil) }
+}
@@ -0,0 +1,168 @@
+package context
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWithJitteredTimeout(t *testing.T) {
+	parent := Background()
+
+	seen := make(map[time.Time]bool)
+	for i := 0; i < 100; i++ {
+		before := time.Now()
+
+		ctx, cancel := WithJitteredTimeout(parent, time.Hour, 0.1)
+		deadline, ok := ctx.Deadline()
+		cancel()
+
+		if !ok {
+			t.Fatalf("Expected deadline to be set.")
+		}
+
+		if timeout := deadline.Sub(before); timeout < 54*time.Minute || timeout > 66*time.Minute+time.Second {
+			t.Errorf("Expected timeout to be within 10%% of 1h. Got %v.", timeout)
+		}
+
+		seen[deadline] = true
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("Expected deadlines to be randomized.")
+	}
+}
+
+func TestWithMaxTimeout(t *testing.T) {
+	parent := WithMaxTimeout(Background(), time.Minute)
+
+	named := WithName(parent, "named")
+
+	for _, c := range []struct {
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{time.Second, time.Second},
+		{time.Hour, time.Minute},
+	} {
+		before := time.Now()
+
+		ctx, cancel := WithTimeout(named, c.timeout)
+		deadline, _ := ctx.Deadline()
+		cancel()
+
+		if timeout := deadline.Sub(before); timeout < c.expected || timeout > c.expected+time.Second {
+			t.Errorf("Expected timeout to be %v. Got %v.", c.expected, timeout)
+		}
+	}
+
+	// The smallest cap applies.
+	if n := nodeOf(WithMaxTimeout(parent, time.Hour)); n.config().maxTimeout != time.Minute {
+		t.Errorf("Expected max timeout to be %v. Got %v.", time.Minute, n.config().maxTimeout)
+	}
+}
+
+func TestWithMaxTimeout_Extension(t *testing.T) {
+	parent := WithMaxTimeout(Background(), 50*time.Millisecond)
+	SetExtensionPolicy(parent, func(Context, time.Duration) bool {
+		return true
+	})
+
+	ctx, cancel := WithTimeout(parent, 10*time.Millisecond)
+	defer cancel()
+
+	before, _ := ctx.Deadline()
+
+	if !ctx.RequestExtension(time.Hour) {
+		t.Fatalf("Expected extension to be granted.")
+	}
+
+	deadline, _ := ctx.Deadline()
+	if limit := before.Add(50 * time.Millisecond); deadline.After(limit) {
+		t.Errorf("Expected deadline to be capped before %v. Got %v.", limit, deadline)
+	}
+
+	// Already at the cap.
+	if ctx.RequestExtension(time.Hour) {
+		t.Errorf("Expected extension past the cap to be denied.")
+	}
+}
+
+func TestEarliestDeadline(t *testing.T) {
+	parent := Background()
+
+	if _, ok := EarliestDeadline(parent); ok {
+		t.Errorf("Expected no deadline.")
+	}
+
+	ctx1, cancel1 := WithTimeout(parent, time.Hour)
+	defer cancel1()
+
+	ctx2, cancel2 := WithTimeout(parent, time.Minute)
+	defer cancel2()
+
+	expected, _ := ctx2.Deadline()
+	if deadline, ok := EarliestDeadline(parent, ctx1, ctx2); !ok || !deadline.Equal(expected) {
+		t.Errorf("Expected deadline to be %v. Got %v.", expected, deadline)
+	}
+}
+
+func TestRemainingBudget(t *testing.T) {
+	parent := Background()
+
+	if budget := RemainingBudget(parent); budget != math.MaxInt64 {
+		t.Errorf("Expected unlimited budget. Got %v.", budget)
+	}
+
+	ctx, cancel := WithTimeout(parent, time.Minute)
+	defer cancel()
+
+	if budget := RemainingBudget(ctx); budget <= 0 || budget > time.Minute {
+		t.Errorf("Expected budget to be at most 1m. Got %v.", budget)
+	}
+
+	expired, cancelExpired := WithTimeout(parent, -time.Second)
+	defer cancelExpired()
+
+	if budget := RemainingBudget(expired); budget != 0 {
+		t.Errorf("Expected no budget left. Got %v.", budget)
+	}
+}
+
+func TestWithCancelDelay(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+
+	ctx, force := WithCancelDelay(parent, 5*time.Millisecond)
+	defer force()
+
+	cancel()
+
+	if ctx.Err() != nil {
+		t.Errorf("Expected context not to be canceled right away. Got %v.", ctx.Err())
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timeout waiting for delayed cancellation.")
+	}
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, ctx.Err())
+	}
+}
+
+func TestWithCancelDelay_Force(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	ctx, force := WithCancelDelay(parent, time.Hour)
+	force()
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, ctx.Err())
+	}
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+}
@@ -0,0 +1,115 @@
+package context
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// debugNode is the representation of a context in the debug tree.
+type debugNode struct {
+	Name            string      `json:"name,omitempty"`
+	Description     string      `json:"description"`
+	Deadline        *time.Time  `json:"deadline,omitempty"`
+	Age             string      `json:"age"`
+	PendingChildren int         `json:"pending_children"`
+	Waits           int         `json:"waits"`
+	Err             string      `json:"err,omitempty"`
+	Children        []debugNode `json:"children,omitempty"`
+}
+
+func newDebugNode(c *ctxImpl) debugNode {
+	d := debugNode{
+		Name:            c.name,
+		Description:     c.String(),
+		Age:             time.Since(c.created).Truncate(time.Millisecond).String(),
+		PendingChildren: c.childrenWg.count(),
+		Waits:           int(c.waits.Load()),
+	}
+
+	if deadline, ok := c.Deadline(); ok {
+		d.Deadline = &deadline
+	}
+
+	if err := c.Err(); err != nil {
+		d.Err = err.Error()
+	}
+
+	for _, kid := range c.liveKids() {
+		d.Children = append(d.Children, newDebugNode(kid))
+	}
+
+	return d
+}
+
+// newRootsDebugNode returns a debug node with the trees of all registered
+// roots as children.
+func newRootsDebugNode() debugNode {
+	d := debugNode{
+		Description: "registered roots",
+	}
+
+	for _, info := range Roots() {
+		if c := lookup(info.Context); c != nil {
+			kid := newDebugNode(c)
+			kid.Name = info.Name
+			d.Children = append(d.Children, kid)
+		}
+	}
+
+	return d
+}
+
+var debugTemplate = template.Must(template.New("tree").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Contexts</title></head>
+<body>
+<ul>{{template "node" .}}</ul>
+</body>
+</html>
+{{define "node"}}<li>
+<b>{{.Description}}</b>
+(age: {{.Age}}, pending children: {{.PendingChildren}}, waits: {{.Waits}}{{if .Deadline}}, deadline: {{.Deadline}}{{end}}{{if .Err}}, err: {{.Err}}{{end}})
+{{if .Children}}<ul>{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}
+</li>{{end}}
+`))
+
+// DebugHandler returns an http.Handler that renders the current tree of
+// contexts derived from root, including pending children, names, deadlines
+// and ages. It renders HTML by default and JSON if the "format" query
+// parameter is "json" (or JSON is explicitly accepted by the client). It is
+// meant to be mounted under /debug/contexts, like net/http/pprof.
+//
+// If root is nil, all contexts registered with RegisterRoot() are rendered.
+func DebugHandler(root Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tree debugNode
+		if root == nil {
+			tree = newRootsDebugNode()
+		} else {
+			c := lookup(root)
+			if c == nil {
+				http.Error(w, "context has no wait support", http.StatusInternalServerError)
+				return
+			}
+
+			tree = newDebugNode(c)
+		}
+
+		if r.URL.Query().Get("format") == "json" ||
+			strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(tree)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugTemplate.Execute(w, tree)
+	})
+}
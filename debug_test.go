@@ -0,0 +1,42 @@
+package context
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	root := Background()
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	worker := EnableWait(WithName(ctx, "worker"))
+	defer worker.Finished()
+
+	rec := httptest.NewRecorder()
+	DebugHandler(root).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/contexts?format=json", nil))
+
+	var tree debugNode
+	if err := json.Unmarshal(rec.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	if len(tree.Children) != 1 || tree.Children[0].PendingChildren != 1 {
+		t.Fatalf("Expected one child with one pending child. Got %+v.", tree)
+	}
+
+	grandChildren := tree.Children[0].Children
+	if len(grandChildren) != 1 || grandChildren[0].Name != "worker" || grandChildren[0].Waits != 1 {
+		t.Errorf("Expected named worker with one wait. Got %+v.", grandChildren)
+	}
+
+	rec = httptest.NewRecorder()
+	DebugHandler(root).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/contexts", nil))
+
+	if body := rec.Body.String(); !strings.Contains(body, "WithName(&#34;worker&#34;)") {
+		t.Errorf("Expected HTML to contain the worker context. Got %q.", body)
+	}
+}
@@ -0,0 +1,88 @@
+package context
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Kind identifies how a Context was derived from its parent (see
+// RegisterDeriveHook()).
+type Kind int
+
+const (
+	// KindCancel is used for WithCancel() and Merge().
+	KindCancel Kind = iota
+
+	// KindDeadline is used for WithDeadline() and WithTimeout() (including
+	// their variations).
+	KindDeadline
+
+	// KindValue is used for WithValues() and WithPprofLabels().
+	KindValue
+
+	// KindName is used for WithName().
+	KindName
+
+	// KindWait is used for EnableWait() (including its variations). In this
+	// case, parent is the Context the work will be reported to.
+	KindWait
+
+	// KindOther is used for all other derivations (WithClock(),
+	// WithMaxTimeout(), CloneDetachedWait(), etc).
+	KindOther
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCancel:
+		return "Cancel"
+	case KindDeadline:
+		return "Deadline"
+	case KindValue:
+		return "Value"
+	case KindName:
+		return "Name"
+	case KindWait:
+		return "Wait"
+	default:
+		return "Other"
+	}
+}
+
+// DeriveHook is called every time a Context is derived from another.
+type DeriveHook func(parent, child Context, kind Kind)
+
+var (
+	deriveHooksMu sync.Mutex
+	deriveHooks   atomic.Pointer[[]DeriveHook]
+)
+
+// RegisterDeriveHook registers a hook to be called (synchronously, by the
+// goroutine deriving the Context) every time a Context is derived from
+// another, after the new Context is fully set up. Hooks are called in
+// registration order and can be used, for example, to attach tracing,
+// enforce naming conventions or count context churn.
+func RegisterDeriveHook(hook DeriveHook) {
+	deriveHooksMu.Lock()
+	defer deriveHooksMu.Unlock()
+
+	var hooks []DeriveHook
+	if current := deriveHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, hook)
+
+	deriveHooks.Store(&hooks)
+}
+
+// derived calls all registered hooks for the given derivation.
+func derived(parent, child Context, kind Kind) {
+	hooks := deriveHooks.Load()
+	if hooks == nil {
+		return
+	}
+
+	for _, hook := range *hooks {
+		hook(parent, child, kind)
+	}
+}
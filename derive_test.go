@@ -0,0 +1,52 @@
+package context
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegisterDeriveHook(t *testing.T) {
+	root := Background()
+
+	var mu sync.Mutex
+	var kinds []Kind
+
+	RegisterDeriveHook(func(parent, child Context, kind Kind) {
+		if child.Root() != root {
+			// Some other test.
+			return
+		}
+
+		if kind != KindWait && child.Parent() != parent {
+			t.Errorf("Expected parent to be %v. Got %v.", child.Parent(), parent)
+		}
+
+		mu.Lock()
+		kinds = append(kinds, kind)
+		mu.Unlock()
+	})
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	timeoutCtx, timeoutCancel := WithTimeout(ctx, time.Hour)
+	defer timeoutCancel()
+
+	named := WithName(timeoutCtx, "named")
+	valued := WithValues(named, valuesKey("key"), 1)
+	EnableWait(valued).Finished()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []Kind{KindCancel, KindDeadline, KindName, KindValue, KindWait}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected kinds to be %v. Got %v.", expected, kinds)
+	}
+	for i, kind := range expected {
+		if kinds[i] != kind {
+			t.Errorf("Expected kind %d to be %v. Got %v.", i, kind, kinds[i])
+		}
+	}
+}
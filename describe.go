@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Description is a snapshot of the state of a Context, meant to be embedded
+// in structured logs and error reports (see Describe()).
+type Description struct {
+	// Name given with WithName(), if any.
+	Name string `json:"name,omitempty"`
+
+	// Same as the String() representation of the Context.
+	Description string `json:"description"`
+
+	// Deadline of the Context and time remaining until it (negative if it
+	// already passed), if there is one. Remaining is in nanoseconds when
+	// marshaled to JSON.
+	Deadline  *time.Time    `json:"deadline,omitempty"`
+	Remaining time.Duration `json:"remaining,omitempty"`
+
+	// Number of children that still did not call Finished().
+	PendingChildren int `json:"pending_children"`
+
+	// Number of Finished() calls this Context still owes its parent.
+	PendingFinished int `json:"pending_finished"`
+
+	// Values for keys registered with RegisterDebugKey() (see DebugValues()),
+	// formatted with fmt.Sprint().
+	Values map[string]string `json:"values,omitempty"`
+
+	// Err() and context.Cause() of the Context, if it is done.
+	Err   string `json:"err,omitempty"`
+	Cause string `json:"cause,omitempty"`
+}
+
+// Describe returns a snapshot of the state of ctx. Contexts without wait
+// support (not derived from a Context) are described with their standard
+// library properties only.
+func Describe(ctx context.Context) Description {
+	d := Description{
+		Description: contextName(ctx),
+	}
+
+	if n := nodeOf(ctx); n != nil {
+		d.Name = n.name
+		d.PendingChildren = n.childrenWg.count()
+		d.PendingFinished = int(n.waits.Load())
+	}
+
+	for name, value := range DebugValues(ctx) {
+		if d.Values == nil {
+			d.Values = make(map[string]string)
+		}
+		d.Values[name] = fmt.Sprint(value)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		d.Deadline = &deadline
+		d.Remaining = deadline.Sub(nodeOf(ctx).getClock().Now())
+	}
+
+	if err := ctx.Err(); err != nil {
+		d.Err = err.Error()
+		d.Cause = context.Cause(ctx).Error()
+	}
+
+	return d
+}
+
+// MarshalJSON implements json.Marshaler by marshaling the Description of the
+// Context.
+func (c *ctxImpl) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Describe(c))
+}
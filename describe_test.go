@@ -0,0 +1,84 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	parent := WithName(Background(), "parent")
+
+	ctx, cancel := WithTimeout(parent, time.Hour)
+	defer cancel()
+
+	EnableWait(ctx)
+	defer ctx.Finished()
+
+	d := Describe(parent)
+	if d.Name != "parent" {
+		t.Errorf("Expected name to be %q. Got %q.", "parent", d.Name)
+	}
+	if d.PendingChildren != 1 {
+		t.Errorf("Expected 1 pending child. Got %d.", d.PendingChildren)
+	}
+
+	d = Describe(ctx)
+	if d.PendingFinished != 1 {
+		t.Errorf("Expected 1 pending Finished() call. Got %d.", d.PendingFinished)
+	}
+	if d.Deadline == nil || d.Remaining <= 0 || d.Remaining > time.Hour {
+		t.Errorf("Expected deadline in the future. Got %v (%v).", d.Deadline, d.Remaining)
+	}
+	if d.Err != "" {
+		t.Errorf("Expected no error. Got %q.", d.Err)
+	}
+}
+
+func TestDescribe_Clock(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	ctx, cancel := WithTimeout(WithClock(Background(), clock), time.Hour)
+	defer cancel()
+
+	if d := Describe(ctx); d.Remaining != time.Hour {
+		t.Errorf("Expected remaining time to be %v. Got %v.", time.Hour, d.Remaining)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	ctx, cancel := WithCancel(WithName(Background(), "ctx"))
+
+	errStop := errors.New("stop")
+	ctx.Parent().CancelChildren(errStop)
+	cancel()
+
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	var d Description
+	if err := json.Unmarshal(data, &d); err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	if d.Err != Canceled.Error() {
+		t.Errorf("Expected error to be %q. Got %q.", Canceled, d.Err)
+	}
+	if d.Cause != errStop.Error() {
+		t.Errorf("Expected cause to be %q. Got %q.", errStop, d.Cause)
+	}
+}
+
+func TestDescribe_Values(t *testing.T) {
+	RegisterDebugKey("described", valuesKey("described"))
+
+	ctx := WithValues(Background(), valuesKey("described"), 42)
+
+	d := Describe(ctx)
+	if d.Values["described"] != "42" {
+		t.Errorf("Expected value %q. Got %q.", "42", d.Values["described"])
+	}
+}
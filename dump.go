@@ -0,0 +1,122 @@
+package context
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var debugMode atomic.Bool
+
+// SetDebug enables or disables debug mode. In debug mode, the stack of the
+// caller creating each context is recorded and included in diagnostics (see
+// DumpPending()) and every context is tracked, so diagnostics include
+// contexts that are otherwise not found from their parents (see
+// Children()). This has a significant performance cost.
+func SetDebug(enabled bool) {
+	debugMode.Store(enabled)
+}
+
+// creationStack returns the current call stack if in debug mode or nil
+// otherwise.
+func creationStack() []uintptr {
+	if !debugMode.Load() {
+		return nil
+	}
+
+	pcs := make([]uintptr, 32)
+
+	// Skip runtime.Callers, creationStack and newCtx.
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// stackOf returns the creation stack of n, if it was recorded (see
+// SetDebug()).
+func (n *node) stackOf() []uintptr {
+	if x := n.extras.Load(); x != nil {
+		return x.stack
+	}
+
+	return nil
+}
+
+// DumpOnSignal writes the tree of unfinished children of root (see
+// DumpPending()) to stderr every time one of the given signals is received.
+// This mirrors the goroutine dump workflow for diagnosing a hung
+// WaitForChildren(). It returns a function that stops listening for the
+// signals.
+func DumpOnSignal(root Context, signals ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, signals...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				DumpPending(os.Stderr, root)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// DumpPending writes the tree of contexts derived from root that still have
+// pending Finished() calls or pending children to w. In debug mode (see
+// SetDebug()), the creation stack of each pending context is also included.
+func DumpPending(w io.Writer, root Context) {
+	c := lookup(root)
+	if c == nil {
+		return
+	}
+
+	var b strings.Builder
+	dumpPending(&b, c, 0)
+
+	io.WriteString(w, b.String())
+}
+
+// dumpPending writes the pending contexts in the tree rooted at c.
+func dumpPending(b *strings.Builder, c *ctxImpl, depth int) {
+	var sub strings.Builder
+	for _, kid := range c.liveKids() {
+		dumpPending(&sub, kid, depth+1)
+	}
+
+	if c.waits.Load() == 0 && c.childrenWg.count() == 0 && sub.Len() == 0 {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+
+	fmt.Fprintf(b, "%s%s (age %s)\n", indent, c,
+		time.Since(c.created).Truncate(time.Millisecond))
+
+	if stack := c.stackOf(); len(stack) > 0 {
+		fmt.Fprintf(b, "%s  created at:\n", indent)
+
+		frames := runtime.CallersFrames(stack)
+		for {
+			frame, more := frames.Next()
+			fmt.Fprintf(b, "%s    %s\n%s        %s:%d\n", indent, frame.Function,
+				indent, frame.File, frame.Line)
+			if !more {
+				break
+			}
+		}
+	}
+
+	b.WriteString(sub.String())
+}
@@ -0,0 +1,42 @@
+package context
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestDumpPending(t *testing.T) {
+	SetDebug(true)
+	defer SetDebug(false)
+
+	root := Background()
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	worker := EnableWait(WithName(ctx, "worker"))
+	defer worker.Finished()
+
+	// Not pending.
+	WithName(root, "idle")
+
+	var b strings.Builder
+	DumpPending(&b, root)
+
+	dump := b.String()
+	if !strings.Contains(dump, `WithName("worker")`) {
+		t.Errorf("Expected dump to contain the worker. Got %q.", dump)
+	}
+	if strings.Contains(dump, `WithName("idle")`) {
+		t.Errorf("Expected dump to not contain the idle context. Got %q.", dump)
+	}
+	if !strings.Contains(dump, "TestDumpPending") {
+		t.Errorf("Expected dump to contain the creation stack. Got %q.", dump)
+	}
+}
+
+func TestDumpOnSignal(t *testing.T) {
+	stop := DumpOnSignal(Background(), syscall.SIGUSR1)
+	stop()
+}
@@ -0,0 +1,78 @@
+package context
+
+// Group is the subset of the golang.org/x/sync/errgroup.Group API used by
+// AdaptGroup(). *errgroup.Group satisfies it.
+type Group interface {
+	Go(fn func() error)
+}
+
+// tryGroup is implemented by groups that support TryGo (like
+// *errgroup.Group).
+type tryGroup interface {
+	TryGo(fn func() error) bool
+}
+
+// AdaptedGroup launches goroutines through an existing Group while also
+// registering them with the wait machinery of a Context. See AdaptGroup().
+type AdaptedGroup struct {
+	ctx Context
+	g   Group
+}
+
+// AdaptGroup returns an AdaptedGroup that launches goroutines through g (for
+// example, an *errgroup.Group) while also registering each of them as a
+// wait-enabled child of ctx. Both g.Wait() and ctx.WaitForChildren() wait for
+// them, so codebases can migrate incrementally. Errors returned by the
+// goroutines are returned to g as usual and also reported to ctx with
+// FinishedErr().
+func AdaptGroup(ctx Context, g Group) *AdaptedGroup {
+	return &AdaptedGroup{
+		ctx: ctx,
+		g:   g,
+	}
+}
+
+// Go calls fn in a new goroutine through the adapted Group.
+func (a *AdaptedGroup) Go(fn func() error) {
+	wrapped, _ := a.wrapChild(fn)
+	a.g.Go(wrapped)
+}
+
+// TryGo calls fn in a new goroutine through the adapted Group if it supports
+// TryGo (like *errgroup.Group with a limit). It returns false if the
+// goroutine was not started. Groups that do not support TryGo always start
+// the goroutine.
+func (a *AdaptedGroup) TryGo(fn func() error) bool {
+	tg, ok := a.g.(tryGroup)
+	if !ok {
+		a.Go(fn)
+		return true
+	}
+
+	wrapped, child := a.wrapChild(fn)
+	if !tg.TryGo(wrapped) {
+		child.Finished()
+		return false
+	}
+
+	return true
+}
+
+// wrapChild registers a new child of the Context and returns a function that
+// calls fn and reports its completion to the child.
+func (a *AdaptedGroup) wrapChild(fn func() error) (func() error, Context) {
+	child := EnableWait(newCtx(stdContext(a.ctx), a.ctx))
+
+	return func() error {
+		untrack := trackGoroutine(child)
+
+		err := runChild(child, func(Context) error {
+			return fn()
+		})
+
+		untrack()
+		child.FinishedErr(err)
+
+		return err
+	}, child
+}
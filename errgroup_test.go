@@ -0,0 +1,119 @@
+package context
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// testGroup mimics the errgroup.Group behavior needed by the tests.
+type testGroup struct {
+	wg sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+
+	limit chan struct{}
+}
+
+func (g *testGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if err := fn(); err != nil {
+			g.mu.Lock()
+			if g.err == nil {
+				g.err = err
+			}
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *testGroup) TryGo(fn func() error) bool {
+	select {
+	case g.limit <- struct{}{}:
+	default:
+		return false
+	}
+
+	g.Go(fn)
+
+	return true
+}
+
+func (g *testGroup) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+func TestAdaptGroup(t *testing.T) {
+	parent := Background()
+
+	g := &testGroup{}
+	a := AdaptGroup(parent, g)
+
+	errFailed := errors.New("failed")
+
+	a.Go(func() error { return nil })
+	a.Go(func() error { return errFailed })
+
+	parent.WaitForChildren()
+
+	if err := g.Wait(); err != errFailed {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+	if err := parent.ChildrenErr(); !errors.Is(err, errFailed) {
+		t.Errorf("Expected children error to be %v. Got %v.", errFailed, err)
+	}
+}
+
+func TestAdaptGroup_TryGo(t *testing.T) {
+	parent := Background()
+
+	g := &testGroup{limit: make(chan struct{}, 1)}
+	a := AdaptGroup(parent, g)
+
+	block := make(chan struct{})
+
+	if !a.TryGo(func() error { <-block; return nil }) {
+		t.Errorf("Expected first TryGo() to succeed.")
+	}
+	if a.TryGo(func() error { return nil }) {
+		t.Errorf("Expected second TryGo() to fail.")
+	}
+
+	close(block)
+
+	parent.WaitForChildren()
+	g.Wait()
+}
+
+func TestAdaptGroup_GoroutineCount(t *testing.T) {
+	parent := Background()
+
+	a := AdaptGroup(parent, &testGroup{})
+
+	started := make(chan struct{})
+	block := make(chan struct{})
+
+	a.Go(func() error {
+		close(started)
+		<-block
+		return nil
+	})
+
+	<-started
+
+	if n := parent.GoroutineCount(); n != 1 {
+		t.Errorf("Expected 1 goroutine. Got %d.", n)
+	}
+
+	close(block)
+	parent.WaitForChildren()
+
+	if n := parent.GoroutineCount(); n != 0 {
+		t.Errorf("Expected no goroutines. Got %d.", n)
+	}
+}
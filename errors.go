@@ -0,0 +1,85 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChildError is the error reported to a parent Context when a child calls
+// FinishedErr() with a non-nil error.
+type ChildError struct {
+	// Child is the Context that reported the error.
+	Child Context
+
+	// Err is the reported error.
+	Err error
+}
+
+func (e *ChildError) Error() string {
+	return fmt.Sprintf("child %v failed: %v", e.Child, e.Err)
+}
+
+func (e *ChildError) Unwrap() error {
+	return e.Err
+}
+
+func (c *ctxImpl) FinishedErr(err error) {
+	if err != nil {
+		childFailures.Add(1)
+
+		if p := c.pNode(); p != nil {
+			childErr := &ChildError{c, err}
+			x := p.ext()
+			x.firstErr.CompareAndSwap(nil, childErr)
+
+			x.errsMu.Lock()
+			x.errs = append(x.errs, childErr)
+			x.errsMu.Unlock()
+		}
+	}
+
+	c.Finished()
+}
+
+func (n *node) ChildrenErr() error {
+	x := n.extras.Load()
+	if x == nil {
+		return nil
+	}
+
+	x.errsMu.Lock()
+	defer x.errsMu.Unlock()
+
+	return errors.Join(x.errs...)
+}
+
+func (n *node) FirstError() error {
+	if x := n.extras.Load(); x != nil {
+		if err := x.firstErr.Load(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsCanceled reports whether err is (or wraps) a cancellation error. It
+// understands errors returned by Err() and errors reported by children (see
+// ChildrenErr()).
+func IsCanceled(err error) bool {
+	return errors.Is(err, Canceled)
+}
+
+// IsDeadline reports whether err is (or wraps) a deadline exceeded error. It
+// understands errors returned by Err() and errors reported by children (see
+// ChildrenErr()).
+func IsDeadline(err error) bool {
+	return errors.Is(err, DeadlineExceeded)
+}
+
+// IsChildFailure reports whether err is (or wraps) an error reported by a
+// child through FinishedErr().
+func IsChildFailure(err error) bool {
+	var childErr *ChildError
+	return errors.As(err, &childErr)
+}
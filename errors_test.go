@@ -0,0 +1,80 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFinishedErr(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	errFailed := errors.New("failed")
+
+	go EnableWait(ctx).FinishedErr(errFailed)
+	go EnableWait(ctx).FinishedErr(nil)
+
+	parent.WaitForChildren()
+
+	err := parent.ChildrenErr()
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+
+	var childErr *ChildError
+	if !errors.As(err, &childErr) || childErr.Child != ctx {
+		t.Errorf("Expected child error for %v. Got %v.", ctx, err)
+	}
+}
+
+func TestFirstError(t *testing.T) {
+	parent := Background()
+
+	if err := parent.FirstError(); err != nil {
+		t.Errorf("Expected no error. Got %v.", err)
+	}
+
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+
+	running := EnableWait(WithName(parent, "running"))
+
+	EnableWait(WithName(parent, "first")).FinishedErr(errFirst)
+	EnableWait(WithName(parent, "second")).FinishedErr(errSecond)
+
+	// Available while other children are still running.
+	if err := parent.FirstError(); !errors.Is(err, errFirst) || errors.Is(err, errSecond) {
+		t.Errorf("Expected error to be %v. Got %v.", errFirst, err)
+	}
+	if running.Err() != nil {
+		t.Errorf("Expected running child not to be canceled. Got %v.", running.Err())
+	}
+
+	running.Finished()
+	parent.WaitForChildren()
+}
+
+func TestErrorClassification(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 1*time.Millisecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	if !IsDeadline(ctx.Err()) || IsCanceled(ctx.Err()) {
+		t.Errorf("Expected %v to be a deadline error.", ctx.Err())
+	}
+
+	childErr := fmt.Errorf("wrapped: %w", &ChildError{ctx, Canceled})
+	if !IsChildFailure(childErr) || !IsCanceled(childErr) {
+		t.Errorf("Expected %v to be a canceled child failure.", childErr)
+	}
+
+	joined := errors.Join(errors.New("other"), ctx.Err())
+	if !IsDeadline(joined) || IsChildFailure(joined) {
+		t.Errorf("Expected %v to be a deadline error.", joined)
+	}
+}
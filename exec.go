@@ -0,0 +1,108 @@
+package context
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// DefaultKillGrace is the default time a command started with ExecCommand()
+// has to exit after being sent SIGTERM due to its context being done, before
+// it is killed.
+const DefaultKillGrace = 5 * time.Second
+
+// Cmd is an exec.Cmd integrated with the wait machinery of a Context (see
+// ExecCommand()).
+type Cmd struct {
+	*exec.Cmd
+
+	ctx Context
+}
+
+// ExecCommand is like exec.CommandContext() but the returned Cmd registers
+// itself as a child of ctx (see EnableWait()) when started and calls
+// FinishedErr() with the result of Wait() when the process exits, so
+// ctx.WaitForChildren() waits for it.
+//
+// When ctx is done, the process is sent SIGTERM and, if it did not exit
+// after WaitDelay (DefaultKillGrace by default), it is killed.
+func ExecCommand(ctx Context, name string, args ...string) *Cmd {
+	child := WithName(ctx, "exec:"+name)
+
+	cmd := exec.CommandContext(stdContext(child), name, args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = DefaultKillGrace
+
+	return &Cmd{
+		Cmd: cmd,
+		ctx: child,
+	}
+}
+
+// Start starts the command and registers it with the context wait
+// machinery. Wait() must be called to release the registration.
+func (c *Cmd) Start() error {
+	EnableWait(c.ctx)
+
+	if err := c.Cmd.Start(); err != nil {
+		c.ctx.FinishedErr(err)
+		return err
+	}
+
+	return nil
+}
+
+// Wait waits for the command to exit and reports its completion (and error,
+// if any) to the context.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.ctx.FinishedErr(err)
+
+	return err
+}
+
+// Run starts the command and waits for it to exit.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+// Output runs the command and returns its standard output.
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+
+	err := c.Run()
+
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its combined standard output
+// and standard error.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	if c.Stderr != nil {
+		return nil, errors.New("exec: Stderr already set")
+	}
+
+	var b bytes.Buffer
+	c.Stdout = &b
+	c.Stderr = &b
+
+	err := c.Run()
+
+	return b.Bytes(), err
+}
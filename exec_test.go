@@ -0,0 +1,68 @@
+package context
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecCommand(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	cmd := ExecCommand(ctx, "echo", "hello")
+
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+	if strings.TrimSpace(string(output)) != "hello" {
+		t.Errorf("Expected output to be %q. Got %q.", "hello", output)
+	}
+
+	ctx.WaitForChildren()
+
+	if err := ctx.ChildrenErr(); err != nil {
+		t.Errorf("Expected no errors. Got %v.", err)
+	}
+}
+
+func TestExecCommand_Canceled(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	cmd := ExecCommand(ctx, "sleep", "10")
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	go func() {
+		cmd.Wait()
+	}()
+
+	cancel()
+
+	start := time.Now()
+	ctx.WaitForChildren()
+
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Expected command to be stopped. Took %v.", d)
+	}
+	if err := ctx.ChildrenErr(); err == nil {
+		t.Errorf("Expected command error.")
+	}
+}
@@ -0,0 +1,66 @@
+package context
+
+import (
+	"context"
+	"expvar"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Package-level counters published by EnableExpvar().
+var (
+	activeContexts  atomic.Int64
+	pendingChildren atomic.Int64
+	waitsInProgress atomic.Int64
+	childFailures   atomic.Int64
+)
+
+var (
+	expvarEnabled atomic.Bool
+	expvarOnce    sync.Once
+)
+
+// EnableExpvar publishes package-level context statistics as the "context"
+// expvar variable (see the expvar package), so they show up under
+// /debug/vars. The variable is a map with the following keys:
+//
+//   - active_contexts: contexts created after EnableExpvar() was called that
+//     are not done and are still reachable.
+//   - pending_children: EnableWait() registrations without a matching
+//     Finished() call.
+//   - waits_in_progress: WaitForChildren() calls (including variations)
+//     currently blocked.
+//   - child_failures: cumulative number of FinishedErr() calls with a non-nil
+//     error.
+//
+// It can be called any number of times.
+func EnableExpvar() {
+	expvarOnce.Do(func() {
+		expvarEnabled.Store(true)
+
+		expvar.Publish("context", expvar.Func(func() any {
+			return map[string]int64{
+				"active_contexts":   activeContexts.Load(),
+				"pending_children":  pendingChildren.Load(),
+				"waits_in_progress": waitsInProgress.Load(),
+				"child_failures":    childFailures.Load(),
+			}
+		}))
+	})
+}
+
+// trackActive counts c as active until it is done or becomes unreachable.
+func trackActive(c *ctxImpl) {
+	activeContexts.Add(1)
+
+	var ended atomic.Bool
+	end := func() {
+		if !ended.Swap(true) {
+			activeContexts.Add(-1)
+		}
+	}
+
+	context.AfterFunc(c.Context, end)
+	runtime.AddCleanup(c, func(end func()) { end() }, end)
+}
@@ -0,0 +1,53 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+)
+
+func contextVars(t *testing.T) map[string]int64 {
+	v := expvar.Get("context")
+	if v == nil {
+		t.Fatalf("Expected context variable to be published.")
+	}
+
+	var vars map[string]int64
+	if err := json.Unmarshal([]byte(v.String()), &vars); err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	return vars
+}
+
+func TestEnableExpvar(t *testing.T) {
+	EnableExpvar()
+	EnableExpvar()
+
+	before := contextVars(t)
+
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	child := EnableWait(WithName(ctx, "child"))
+
+	during := contextVars(t)
+	if n := during["pending_children"] - before["pending_children"]; n != 1 {
+		t.Errorf("Expected 1 more pending child. Got %d.", n)
+	}
+	if n := during["active_contexts"] - before["active_contexts"]; n < 3 {
+		t.Errorf("Expected at least 3 more active contexts. Got %d.", n)
+	}
+
+	child.FinishedErr(errors.New("failed"))
+	ctx.WaitForChildren()
+
+	after := contextVars(t)
+	if n := after["pending_children"] - before["pending_children"]; n != 0 {
+		t.Errorf("Expected no more pending children. Got %d.", n)
+	}
+	if n := after["child_failures"] - before["child_failures"]; n != 1 {
+		t.Errorf("Expected 1 more child failure. Got %d.", n)
+	}
+}
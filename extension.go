@@ -0,0 +1,294 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ExtensionPolicy decides if the deadline of ctx can be extended by d (see
+// RequestExtension()).
+type ExtensionPolicy func(ctx Context, d time.Duration) bool
+
+// SetExtensionPolicy sets the policy used to decide on deadline extension
+// requests from contexts derived from ctx. It only applies to contexts
+// created with WithDeadline() or WithTimeout() after it is set and it can not
+// extend deadlines past the deadline of ctx itself.
+func SetExtensionPolicy(ctx Context, policy ExtensionPolicy) {
+	n := nodeOf(ctx)
+	if n == nil {
+		misuse(ctx, "SetExtensionPolicy() called on context %v without wait support", ctx)
+		return
+	}
+
+	n.childExtension.Store(&policy)
+}
+
+func (c *ctxImpl) RequestExtension(d time.Duration) bool {
+	e, ok := c.Context.Value(extendableKey{}).(*extendableCtx)
+	if !ok || e.ctx != c.Context || c.extension == nil {
+		// Not directly created with WithDeadline() or WithTimeout() (see
+		// withDeadline()) or no policy.
+		return false
+	}
+
+	if !(*c.extension)(c, d) {
+		return false
+	}
+
+	return e.extend(d)
+}
+
+// withDeadline returns a copy of parent that is done when the deadline
+// passes. Deadlines that might be extended or that use a Clock other than the
+// real one use an extendableCtx. Otherwise, a standard library deadline is
+// used. The returned function releases the associated resources.
+func withDeadline(parent context.Context, deadline, max time.Time, clock Clock, extendable bool) (context.Context, func()) {
+	if _, ok := clock.(realClock); ok && !extendable {
+		return context.WithDeadline(parent, deadline)
+	}
+
+	e := newExtendableCtx(parent, deadline, max, clock)
+
+	return e, e.stopTimer
+}
+
+// extendableKey is used to find the extendableCtx a context was directly
+// derived from.
+type extendableKey struct{}
+
+// extendableCtx is a standard library context with a deadline that can be
+// extended. It is not a standard library cancelable context, so the
+// cancelable context derived from it (see WithDeadline()) gets
+// DeadlineExceeded as its error when the deadline passes and standard library
+// contexts derived from that also report DeadlineExceeded.
+type extendableCtx struct {
+	parent context.Context
+
+	// The cancelable context derived from this one by WithDeadline(), if
+	// any.
+	ctx context.Context
+
+	clock Clock
+
+	done chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	deadline time.Time
+
+	// Deadlines can not be extended past this (see WithMaxTimeout()), if not
+	// zero.
+	max time.Time
+
+	// Functions registered with AfterFunc().
+	afterFuncs []*afterFunc
+
+	// Stops the current deadline timer. With the real clock, the timer is
+	// kept directly instead, saving an allocation.
+	stop  func() bool
+	timer *time.Timer
+
+	// Stops watching the parent for completion.
+	stopParent func() bool
+}
+
+type afterFunc struct {
+	f func()
+}
+
+func newExtendableCtx(parent context.Context, deadline, max time.Time, clock Clock) *extendableCtx {
+	e := &extendableCtx{
+		parent:   parent,
+		clock:    clock,
+		done:     make(chan struct{}),
+		deadline: deadline,
+		max:      max,
+	}
+
+	d := deadline.Sub(clock.Now())
+	if d <= 0 {
+		// Already expired. Like the standard library, report it right away.
+		e.stop = func() bool { return false }
+		e.expire()
+
+		return e
+	}
+
+	e.mu.Lock()
+	e.startTimer(d)
+	e.mu.Unlock()
+
+	if parent.Done() != nil {
+		e.stopParent = context.AfterFunc(parent, func() {
+			e.finish(parent.Err())
+		})
+	}
+
+	if err := parent.Err(); err != nil {
+		// Like the standard library, report it right away.
+		e.finish(err)
+	}
+
+	return e
+}
+
+// startTimer starts a timer that expires the context after d. It must be
+// called with the lock held.
+func (e *extendableCtx) startTimer(d time.Duration) {
+	if _, ok := e.clock.(realClock); ok {
+		e.timer = time.AfterFunc(d, e.expire)
+		return
+	}
+
+	e.stop = e.clock.AfterFunc(d, e.expire)
+}
+
+// stopTimerLocked stops the deadline timer. It returns false if it already
+// fired. It must be called with the lock held.
+func (e *extendableCtx) stopTimerLocked() bool {
+	if e.timer != nil {
+		return e.timer.Stop()
+	}
+
+	return e.stop()
+}
+
+// stopTimer stops the deadline timer, if it did not fire yet, and stops
+// watching the parent.
+func (e *extendableCtx) stopTimer() {
+	e.mu.Lock()
+	e.stopTimerLocked()
+	stopParent := e.stopParent
+	e.mu.Unlock()
+
+	if stopParent != nil {
+		stopParent()
+	}
+}
+
+func (e *extendableCtx) expire() {
+	e.finish(DeadlineExceeded)
+}
+
+// finish marks the context as done with the given error, if it is not done
+// yet, and calls all functions registered with AfterFunc().
+func (e *extendableCtx) finish(err error) {
+	e.mu.Lock()
+	if e.err != nil {
+		e.mu.Unlock()
+		return
+	}
+
+	e.err = err
+	close(e.done)
+
+	e.stopTimerLocked()
+	afterFuncs := e.afterFuncs
+	e.afterFuncs = nil
+
+	e.mu.Unlock()
+
+	for _, a := range afterFuncs {
+		a.f()
+	}
+}
+
+// AfterFunc arranges for f to be called once the context is done. It is used
+// by the standard library to propagate cancellation to contexts derived from
+// this one without starting a goroutine.
+func (e *extendableCtx) AfterFunc(f func()) func() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		go f()
+		return func() bool { return false }
+	}
+
+	a := &afterFunc{f}
+	e.afterFuncs = append(e.afterFuncs, a)
+
+	return func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		for i, other := range e.afterFuncs {
+			if other == a {
+				e.afterFuncs = append(e.afterFuncs[:i], e.afterFuncs[i+1:]...)
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func (e *extendableCtx) Deadline() (time.Time, bool) {
+	e.mu.Lock()
+	deadline := e.deadline
+	e.mu.Unlock()
+
+	if parentDeadline, ok := e.parent.Deadline(); ok && parentDeadline.Before(deadline) {
+		return parentDeadline, true
+	}
+
+	return deadline, true
+}
+
+func (e *extendableCtx) Done() <-chan struct{} {
+	return e.done
+}
+
+func (e *extendableCtx) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+func (e *extendableCtx) Value(key any) any {
+	if key == (extendableKey{}) {
+		return e
+	}
+
+	return e.parent.Value(key)
+}
+
+func (e *extendableCtx) String() string {
+	deadline, _ := e.Deadline()
+
+	return fmt.Sprintf("%s.WithDeadline(%s [%s])", contextName(e.parent),
+		deadline, deadline.Sub(e.clock.Now()).Truncate(time.Millisecond))
+}
+
+func (e *extendableCtx) extend(d time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	deadline := e.deadline.Add(d)
+	if !e.max.IsZero() && deadline.After(e.max) {
+		deadline = e.max
+	}
+
+	if !deadline.After(e.deadline) {
+		// Already at the cap.
+		return false
+	}
+
+	if !deadline.After(e.clock.Now()) {
+		// Would expire right away anyway.
+		return false
+	}
+
+	if e.err != nil || !e.stopTimerLocked() {
+		// Already done.
+		return false
+	}
+
+	e.deadline = deadline
+	e.startTimer(e.deadline.Sub(e.clock.Now()))
+
+	return true
+}
@@ -0,0 +1,88 @@
+package context
+
+import (
+	stdcontext "context"
+	"testing"
+	"time"
+)
+
+func TestRequestExtension(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	granted := 0
+	SetExtensionPolicy(parent, func(ctx Context, d time.Duration) bool {
+		if granted > 0 {
+			return false
+		}
+		granted++
+
+		return true
+	})
+
+	ctx, cancel := WithTimeout(parent, 20*time.Millisecond)
+	defer cancel()
+
+	before, _ := ctx.Deadline()
+
+	if !ctx.RequestExtension(1 * time.Hour) {
+		t.Fatalf("Expected extension to be granted.")
+	}
+	if ctx.RequestExtension(1 * time.Hour) {
+		t.Errorf("Expected second extension to be denied.")
+	}
+
+	after, _ := ctx.Deadline()
+	if !after.Equal(before.Add(1 * time.Hour)) {
+		t.Errorf("Expected deadline to be %v. Got %v.", before.Add(1*time.Hour), after)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if err := ctx.Err(); err != nil {
+		t.Errorf("Expected extended context to not be done. Got %v.", err)
+	}
+}
+
+func TestRequestExtension_Expired(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	SetExtensionPolicy(parent, func(Context, time.Duration) bool { return true })
+
+	ctx, cancel := WithTimeout(parent, 1*time.Millisecond)
+	defer cancel()
+
+	child, cancelChild := WithCancel(ctx)
+	defer cancelChild()
+
+	std, cancelStd := stdcontext.WithCancel(ctx)
+	defer cancelStd()
+
+	<-ctx.Done()
+
+	if ctx.RequestExtension(1 * time.Hour) {
+		t.Errorf("Expected extension of expired context to fail.")
+	}
+	if err := ctx.Err(); err != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+	if err := child.Err(); err != DeadlineExceeded {
+		t.Errorf("Expected child error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+
+	<-std.Done()
+
+	if err := std.Err(); err != stdcontext.DeadlineExceeded {
+		t.Errorf("Expected standard library child error to be %v. Got %v.", stdcontext.DeadlineExceeded, err)
+	}
+}
+
+func TestRequestExtension_NoPolicy(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 1*time.Hour)
+	defer cancel()
+
+	if ctx.RequestExtension(1 * time.Hour) {
+		t.Errorf("Expected extension without policy to fail.")
+	}
+}
@@ -0,0 +1,125 @@
+package context
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FanOut calls fn for each of the given inputs, with at most limit calls
+// running concurrently (no limit if limit <= 0). Each call gets its own
+// wait-enabled child Context and FanOut only returns after all of them
+// finished.
+//
+// Results are returned in input order. If any call returns an error, the
+// Context passed to all other calls is canceled, no new calls are started and
+// the first error is returned. If ctx is done before all calls are started,
+// its error is returned.
+func FanOut[T, R any](ctx Context, inputs []T, limit int, fn func(Context, T) (R, error)) ([]R, error) {
+	fanCtx, cancel := WithCancel(ctx)
+	defer cancel()
+
+	if limit <= 0 || limit > len(inputs) {
+		limit = len(inputs)
+	}
+
+	results := make([]R, len(inputs))
+	slots := make(chan struct{}, limit)
+
+	var once sync.Once
+	var firstErr error
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for i, input := range inputs {
+		select {
+		case slots <- struct{}{}:
+		case <-fanCtx.Done():
+		}
+
+		if fanCtx.Err() != nil {
+			if err := ctx.Err(); err != nil {
+				fail(err)
+			}
+			break
+		}
+
+		go func(ctx Context, i int, input T) {
+			untrack := trackGoroutine(ctx)
+			defer func() {
+				<-slots
+				untrack()
+				ctx.Finished()
+			}()
+
+			var result R
+			err := runChild(ctx, func(ctx Context) error {
+				var err error
+				result, err = fn(ctx, input)
+				return err
+			})
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			results[i] = result
+		}(EnableWait(WithName(fanCtx, fmt.Sprintf("fan-out-%d", i))), i, input)
+	}
+
+	lookup(fanCtx).waitForChildren()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return results, nil
+}
+
+// FanIn merges the given channels into a single one. The returned channel is
+// closed after all the given channels are closed or ctx is done.
+func FanIn[T any](ctx Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	mergeCtx := WithName(ctx, "fan-in")
+
+	for _, ch := range channels {
+		go func(ctx Context, ch <-chan T) {
+			untrack := trackGoroutine(ctx)
+			defer func() {
+				untrack()
+				ctx.Finished()
+			}()
+
+			runChild(ctx, func(ctx Context) error {
+				for {
+					select {
+					case v, ok := <-ch:
+						if !ok {
+							return nil
+						}
+
+						select {
+						case out <- v:
+						case <-ctx.Done():
+							return nil
+						}
+					case <-ctx.Done():
+						return nil
+					}
+				}
+			})
+		}(EnableWait(WithName(mergeCtx, "fan-in-input")), ch)
+	}
+
+	go func() {
+		lookup(mergeCtx).waitForChildren()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,88 @@
+package context
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFanOut(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	var running, maxRunning atomic.Int32
+
+	inputs := []int{1, 2, 3, 4, 5, 6}
+	results, err := FanOut(ctx, inputs, 2, func(ctx Context, input int) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return input * 10, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	for i, result := range results {
+		if result != inputs[i]*10 {
+			t.Errorf("Expected result %d to be %d. Got %d.", i, inputs[i]*10, result)
+		}
+	}
+	if n := maxRunning.Load(); n > 2 {
+		t.Errorf("Expected at most 2 concurrent calls. Got %d.", n)
+	}
+}
+
+func TestFanOut_Error(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	errFailed := errors.New("failed")
+
+	_, err := FanOut(ctx, []int{1, 2, 3}, 0, func(ctx Context, input int) (int, error) {
+		if input == 2 {
+			return 0, errFailed
+		}
+
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	})
+	if err != errFailed {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+}
+
+func TestFanIn(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	a, b := make(chan int), make(chan int)
+	go func() {
+		a <- 1
+		close(a)
+	}()
+	go func() {
+		b <- 2
+		close(b)
+	}()
+
+	sum := 0
+	for v := range FanIn(ctx, a, b) {
+		sum += v
+	}
+
+	if sum != 3 {
+		t.Errorf("Expected sum to be 3. Got %d.", sum)
+	}
+}
@@ -0,0 +1,81 @@
+package context
+
+import (
+	"log"
+	"runtime"
+	"time"
+)
+
+func (n *node) GoroutineCount() int {
+	return int(n.goroutines.Load())
+}
+
+// trackGoroutine counts a goroutine launched for ctx by one of the managed
+// APIs (Go(), Pool, FanOut(), etc) in ctx and all its ancestors. The returned
+// function must be called when the goroutine is about to return, before
+// reporting completion.
+func trackGoroutine(ctx Context) (untrack func()) {
+	n := nodeOf(ctx)
+	n.addGoroutines(1)
+
+	return func() {
+		n.addGoroutines(-1)
+	}
+}
+
+func (n *node) addGoroutines(delta int64) {
+	for n != nil {
+		n.goroutines.Add(delta)
+
+		if n.parent == nil {
+			break
+		}
+
+		n = nodeOf(n.parent)
+	}
+}
+
+// WatchGoroutines checks, every interval, if the number of goroutines in the
+// process (see runtime.NumGoroutine()) exceeds the number of goroutines
+// launched through the managed APIs under root (see GoroutineCount()) by more
+// than slack, which should account for all goroutines expected to run outside
+// of the tree. When it does, a warning is logged (with the standard library
+// logger), once until the counts converge again. This helps hunting leaks
+// caused by raw go statements that bypass Finished().
+//
+// It returns a function that stops the checks. No warnings are logged after
+// it returns.
+func WatchGoroutines(root Context, interval time.Duration, slack int) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		defer ticker.Stop()
+
+		warned := false
+		for {
+			select {
+			case <-ticker.C:
+				// Discount this goroutine.
+				total := runtime.NumGoroutine() - 1
+				managed := root.GoroutineCount()
+
+				diverged := total-managed > slack
+				if diverged && !warned {
+					log.Printf("context: %d goroutines running but only %d launched under %v (slack %d)",
+						total, managed, root, slack)
+				}
+				warned = diverged
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+	}
+}
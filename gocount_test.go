@@ -0,0 +1,70 @@
+package context
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoroutineCount(t *testing.T) {
+	root := Background()
+	ctx := WithName(root, "ctx")
+	sibling := WithName(root, "sibling")
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		Go(ctx, func(ctx Context) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	if n := ctx.GoroutineCount(); n != 3 {
+		t.Errorf("Expected 3 goroutines. Got %d.", n)
+	}
+	if n := root.GoroutineCount(); n != 3 {
+		t.Errorf("Expected 3 goroutines under root. Got %d.", n)
+	}
+	if n := sibling.GoroutineCount(); n != 0 {
+		t.Errorf("Expected no goroutines under sibling. Got %d.", n)
+	}
+
+	close(release)
+	ctx.WaitForChildren()
+
+	if n := root.GoroutineCount(); n != 0 {
+		t.Errorf("Expected no goroutines. Got %d.", n)
+	}
+}
+
+func TestWatchGoroutines(t *testing.T) {
+	var b bytes.Buffer
+	log.SetOutput(&b)
+	defer log.SetOutput(os.Stderr)
+
+	root := Background()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// Raw goroutine not tracked.
+	go func() {
+		<-release
+	}()
+
+	stop := WatchGoroutines(root, 1*time.Millisecond, 0)
+	time.Sleep(10 * time.Millisecond)
+	stop()
+
+	if !strings.Contains(b.String(), "goroutines running") {
+		t.Errorf("Expected a warning. Got %q.", b.String())
+	}
+}
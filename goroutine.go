@@ -0,0 +1,112 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"runtime/trace"
+	"time"
+)
+
+// Go runs fn in a new goroutine with a wait-enabled child of ctx, so calling
+// WaitForChildren() on ctx waits for it to return. The error returned by fn
+// (if any) is reported with FinishedErr().
+//
+// If ctx carries profiler labels (see WithPprofLabels()), they are applied to
+// the new goroutine. If trace tasks are enabled (see SetTraceTasks()), the
+// goroutine is traced as a task.
+func Go(ctx Context, fn func(Context) error) {
+	goOn(ctx, ctx, fn, nil, false)
+}
+
+// GoWithTimeout is like Go() but fn gets its own context with the given
+// timeout (see WithTimeout()). The goroutine still reports to ctx and the
+// timeout context is canceled when fn returns.
+func GoWithTimeout(ctx Context, d time.Duration, fn func(Context) error) {
+	child, cancel := WithTimeout(ctx, d)
+	goOn(ctx, child, fn, cancel, false)
+}
+
+// GoTagged is like Go() but the child is tagged with the given tags (see
+// EnableWaitTagged()), so it can be waited on with WaitForChildrenTagged()
+// and is scheduled as its first tag when ctx has fair scheduling enabled
+// (see SetFairScheduling()).
+func GoTagged(ctx Context, fn func(Context) error, tags ...string) {
+	goOn(ctx, ctx, fn, nil, false, tags...)
+}
+
+// goOn runs fn in a new goroutine with a wait-enabled child of ctx that
+// reports to target (ctx itself or one of its ancestors). If not nil, done
+// is called after fn returns and before reporting completion. If try is true,
+// registration errors are returned instead of blocking or being reported as
+// misuse (see enableWait()). Otherwise, they are reported and also returned.
+// Either way, fn is not run if the child could not be registered. The child is
+// tagged with the given tags, if any, and it is canceled when fn returns (or
+// with CancelChildren() on any of its ancestors).
+func goOn(target, ctx Context, fn func(Context) error, done func(), try bool, tags ...string) error {
+	std, endTask := startTraceTask(ctx)
+	std, cancel := context.WithCancelCause(std)
+
+	c := newCtx(std, ctx)
+	c.cancel = cancel
+	c.track()
+	if target != ctx {
+		c.waitOn = target
+	}
+
+	if len(tags) > 0 {
+		c.ext().tags = tags
+		c.addTagged(c.pNode())
+	}
+
+	child, err := enableWaitFor(ctx, c, 1, "Go", try)
+	if err != nil {
+		c.finishTagged(c.pNode())
+		cancel(nil)
+		endTask()
+		if done != nil {
+			done()
+		}
+
+		return err
+	}
+
+	go func() {
+		untrack := trackGoroutine(child)
+
+		applyPprofLabels(child)
+
+		region := trace.StartRegion(child, "run")
+		err := runChild(child, fn)
+		region.End()
+
+		cancel(nil)
+		endTask()
+		if done != nil {
+			done()
+		}
+		untrack()
+		child.FinishedErr(err)
+	}()
+
+	return nil
+}
+
+// runChild calls fn with child. All managed goroutines run user code through
+// it. If child has a context to cancel on panics (see WithCancelOnPanic()),
+// panics are recovered, reported as the returned error and the context is
+// canceled.
+func runChild(child Context, fn func(Context) error) error {
+	target := nodeOf(child).config().panicCancel
+	if target == nil {
+		return fn(child)
+	}
+
+	err := runRecovered(child, fn)
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		target.cancelWith(panicErr)
+	}
+
+	return err
+}
@@ -0,0 +1,51 @@
+package context
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGo(t *testing.T) {
+	parent := Background()
+
+	errFailed := errors.New("failed")
+
+	value := 0
+	Go(parent, func(ctx Context) error {
+		value = 1
+		return errFailed
+	})
+
+	parent.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+	if err := parent.ChildrenErr(); !errors.Is(err, errFailed) {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+}
+
+func TestGoWithTimeout(t *testing.T) {
+	parent := Background()
+
+	for i := 0; i < 3; i++ {
+		GoWithTimeout(parent, 1*time.Millisecond, func(ctx Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	parent.WaitForChildren()
+
+	if err := parent.ChildrenErr(); !errors.Is(err, DeadlineExceeded) {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+	if n := parent.WaitStats().ChildrenFinished; n != 3 {
+		t.Errorf("Expected 3 finished children. Got %d.", n)
+	}
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+}
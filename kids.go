@@ -0,0 +1,102 @@
+package context
+
+import (
+	"sync"
+	"weak"
+)
+
+// kids keeps track of the contexts derived from a context. References are
+// weak so tracking does not keep otherwise unreachable contexts alive.
+//
+// Only contexts that might need to be found from their ancestors are tracked
+// (see track()), so deriving contexts like WithName() ones does not pay for
+// it.
+type kids struct {
+	mu sync.Mutex
+
+	list []weak.Pointer[ctxImpl]
+
+	// Size of the list after the last time it was pruned.
+	pruned int
+}
+
+func (k *kids) add(c *ctxImpl) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if len(k.list) >= 2*k.pruned+16 {
+		k.prune()
+	}
+
+	k.list = append(k.list, weak.Make(c))
+}
+
+// live returns all tracked contexts that are still reachable.
+func (k *kids) live() []*ctxImpl {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.prune()
+
+	live := make([]*ctxImpl, 0, len(k.list))
+	for _, p := range k.list {
+		if c := p.Value(); c != nil {
+			live = append(live, c)
+		}
+	}
+
+	return live
+}
+
+// prune removes references to unreachable contexts. It must be called with
+// the lock held.
+func (k *kids) prune() {
+	list := k.list[:0]
+	for _, p := range k.list {
+		if p.Value() != nil {
+			list = append(list, p)
+		}
+	}
+
+	clear(k.list[len(list):])
+	k.list = list
+	k.pruned = len(list)
+}
+
+// track makes c (and its untracked ancestors) reachable from its parent. It
+// must be called for cancelable contexts (see CancelChildren()), when
+// registering wait-enabled contexts (see Children()) and for anything else
+// that needs to be found from an ancestor.
+func (c *ctxImpl) track() {
+	for c.parent != nil && c.tracked.CompareAndSwap(false, true) {
+		p := lookup(c.parent)
+		if p == nil {
+			return
+		}
+
+		p.kidsList().add(c)
+
+		c = p
+	}
+}
+
+// kidsList returns the tracked children of n, creating the list if needed.
+func (n *node) kidsList() *kids {
+	if k := n.kids.Load(); k != nil {
+		return k
+	}
+
+	n.kids.CompareAndSwap(nil, &kids{})
+
+	return n.kids.Load()
+}
+
+// liveKids returns all tracked children of n that are still reachable.
+func (n *node) liveKids() []*ctxImpl {
+	k := n.kids.Load()
+	if k == nil {
+		return nil
+	}
+
+	return k.live()
+}
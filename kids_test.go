@@ -0,0 +1,46 @@
+package context
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestKids_Weak(t *testing.T) {
+	root := Background()
+
+	kept, cancel := WithCancel(root)
+	defer cancel()
+
+	for i := 0; i < 100; i++ {
+		WithCancel(root)
+	}
+
+	runtime.GC()
+
+	live := lookup(root).liveKids()
+	if len(live) != 1 || live[0] != kept {
+		t.Errorf("Expected only the kept context to be live. Got %d contexts.", len(live))
+	}
+
+	runtime.KeepAlive(kept)
+}
+
+func TestKids_Track(t *testing.T) {
+	root := Background()
+
+	named := WithName(root, "named")
+	if live := lookup(root).liveKids(); len(live) != 0 {
+		t.Errorf("Expected named context not to be tracked. Got %d contexts.", len(live))
+	}
+
+	// Tracking a descendant also tracks the ancestors it needs to be found.
+	child, cancel := WithCancel(named)
+	defer cancel()
+
+	if live := lookup(root).liveKids(); len(live) != 1 || live[0] != named {
+		t.Errorf("Expected named context to be tracked. Got %d contexts.", len(live))
+	}
+	if live := lookup(named).liveKids(); len(live) != 1 || live[0] != child {
+		t.Errorf("Expected child to be tracked. Got %d contexts.", len(live))
+	}
+}
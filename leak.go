@@ -0,0 +1,94 @@
+package context
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var leakWarnings atomic.Bool
+
+// SetLeakWarnings enables or disables leak warnings. When enabled, contexts
+// passed to EnableWait() are tracked and, if one becomes unreachable while
+// still having pending Finished() calls, a warning including its name and
+// the site where it was wait-enabled is logged (with the standard library
+// log package). This catches leaked registrations even outside of tests.
+//
+// Only contexts wait-enabled while warnings are enabled are tracked.
+func SetLeakWarnings(enabled bool) {
+	leakWarnings.Store(enabled)
+}
+
+// leakInfo holds what is needed to report a leaked context. It must not
+// reference the context node itself, so the node can become unreachable.
+type leakInfo struct {
+	pending atomic.Int64
+
+	name  string
+	stack []uintptr
+
+	// Stack of the first EnableWait() caller.
+	site []uintptr
+}
+
+func (n *node) trackLeak(count int) {
+	x := n.ext()
+
+	l := x.leak.Load()
+	if l == nil {
+		site := make([]uintptr, 8)
+
+		l = &leakInfo{
+			name:  n.name,
+			stack: n.stackOf(),
+			site:  site[:runtime.Callers(4, site)],
+		}
+
+		if x.leak.CompareAndSwap(nil, l) {
+			runtime.AddCleanup(n, reportLeak, l)
+		} else {
+			l = x.leak.Load()
+		}
+	}
+
+	l.pending.Add(int64(count))
+}
+
+func reportLeak(l *leakInfo) {
+	pending := l.pending.Load()
+	if pending <= 0 {
+		return
+	}
+
+	name := "unnamed context"
+	if l.name != "" {
+		name = fmt.Sprintf("context %q", l.name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "context: %s became unreachable with %d pending Finished() calls", name, pending)
+
+	writeStack(&b, "wait enabled at", l.site)
+	writeStack(&b, "created at", l.stack)
+
+	log.Print(b.String())
+}
+
+func writeStack(b *strings.Builder, title string, stack []uintptr) {
+	if len(stack) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n  %s:", title)
+
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(b, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+}
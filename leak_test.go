@@ -0,0 +1,64 @@
+package context
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestSetLeakWarnings(t *testing.T) {
+	SetLeakWarnings(true)
+	defer SetLeakWarnings(false)
+
+	var out syncBuffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	parent := Background()
+
+	func() {
+		EnableWait(WithName(parent, "leaked"))
+		EnableWait(WithName(parent, "finished")).Finished()
+	}()
+
+	deadline := time.Now().Add(1 * time.Second)
+	for !strings.Contains(out.String(), `"leaked"`) && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	logged := out.String()
+	if !strings.Contains(logged, `context "leaked" became unreachable with 1 pending Finished() calls`) {
+		t.Errorf("Expected leak warning. Got %q.", logged)
+	}
+	if !strings.Contains(logged, "TestSetLeakWarnings") {
+		t.Errorf("Expected leak warning to contain the wait enabled site. Got %q.", logged)
+	}
+	if strings.Contains(logged, `"finished"`) {
+		t.Errorf("Expected no leak warning for finished context. Got %q.", logged)
+	}
+}
@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"time"
+)
+
+// Lifecycle holds the times of the main events in the life of a Context (see
+// Context.Lifecycle()).
+type Lifecycle struct {
+	// When the Context was created.
+	Created time.Time
+
+	// When the Context was canceled (or its deadline passed). Zero if it is
+	// not done or if it was canceled by a standard library context (in which
+	// case the time is unknown). Explicit cancellations are only recorded
+	// while wait timings are enabled (see SetWaitTimings()).
+	Canceled time.Time
+
+	// When all pending children last finished their work (the end of the
+	// last wait round). Zero if no children ever finished. Only recorded
+	// while wait timings are enabled (see SetWaitTimings()).
+	ChildrenFinished time.Time
+}
+
+// Age returns the time since the Context was created.
+func (l Lifecycle) Age() time.Duration {
+	return time.Since(l.Created)
+}
+
+// Teardown returns the time between cancellation and all children finishing
+// their work or zero if the Context is not done or children did not finish
+// after it was canceled.
+func (l Lifecycle) Teardown() time.Duration {
+	if l.Canceled.IsZero() || l.ChildrenFinished.Before(l.Canceled) {
+		return 0
+	}
+
+	return l.ChildrenFinished.Sub(l.Canceled)
+}
+
+func (c *ctxImpl) Lifecycle() Lifecycle {
+	return Lifecycle{
+		Created:          c.created,
+		Canceled:         c.canceledTime(),
+		ChildrenFinished: c.childrenWg.lastFinished(),
+	}
+}
+
+// canceledTime returns the time c was canceled or the zero time if it is not
+// done. Only cancellations through cancelWith() are recorded, so for anything
+// else it is inferred from the deadline or from the ancestor that was
+// canceled.
+func (c *ctxImpl) canceledTime() time.Time {
+	for c != nil {
+		if c.Err() == nil {
+			return time.Time{}
+		}
+
+		if t := c.canceledAt.Load(); t != 0 {
+			return time.Unix(0, t)
+		}
+
+		if context.Cause(c) == DeadlineExceeded {
+			if deadline, ok := c.Deadline(); ok {
+				return deadline
+			}
+		}
+
+		if c.parent == nil {
+			break
+		}
+
+		c = lookup(c.parent)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,51 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	SetWaitTimings(true)
+	defer SetWaitTimings(false)
+
+	before := time.Now()
+
+	parent, cancel := WithCancel(Background())
+	child, cancelChild := WithCancel(parent)
+	defer cancelChild()
+
+	worker := EnableWait(WithName(parent, "worker"))
+
+	l := parent.Lifecycle()
+	if l.Created.Before(before) || !l.Canceled.IsZero() || !l.ChildrenFinished.IsZero() {
+		t.Errorf("Unexpected lifecycle %+v.", l)
+	}
+
+	cancel()
+	time.Sleep(1 * time.Millisecond)
+	worker.Finished()
+
+	l = parent.Lifecycle()
+	if l.Canceled.IsZero() || l.ChildrenFinished.IsZero() {
+		t.Fatalf("Expected cancel and children finished times. Got %+v.", l)
+	}
+	if l.Teardown() < 1*time.Millisecond {
+		t.Errorf("Expected teardown to take at least 1ms. Got %v.", l.Teardown())
+	}
+
+	// Inherited from the parent.
+	if canceled := child.Lifecycle().Canceled; !canceled.Equal(l.Canceled) {
+		t.Errorf("Expected child cancel time to be %v. Got %v.", l.Canceled, canceled)
+	}
+}
+
+func TestLifecycle_Deadline(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), -time.Second)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	if canceled := ctx.Lifecycle().Canceled; !canceled.Equal(deadline) {
+		t.Errorf("Expected cancel time to be %v. Got %v.", deadline, canceled)
+	}
+}
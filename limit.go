@@ -0,0 +1,168 @@
+package context
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrTooManyChildren is returned when registering a child would exceed the
+// limit set with SetMaxChildren().
+var ErrTooManyChildren = errors.New("context: too many children")
+
+// childLimit is a limit on the number of pending children of a context.
+type childLimit struct {
+	sem   *semaphore
+	block bool
+}
+
+// heldSlots are the slots a context holds on the limits of its parent, one
+// entry per slot, so each is released to the limit it was acquired from.
+type heldSlots struct {
+	mu   sync.Mutex
+	sems []*semaphore
+}
+
+// SetMaxChildren limits the number of pending wait registrations (see
+// EnableWait()) of children of ctx to n, protecting against unbounded
+// fan-out. Registrations beyond the limit block until other children finish
+// if block is true. Otherwise, they are rejected: TryEnableWait() and TryGo()
+// return ErrTooManyChildren and all other registration functions report a
+// misuse (see SetMisusePolicy()). A non-positive n removes the limit.
+//
+// It should be called before any children are registered.
+func SetMaxChildren(ctx Context, n int, block bool) {
+	node := nodeOf(ctx)
+	if node == nil {
+		misuse(ctx, "SetMaxChildren() called on context %v without wait support", ctx)
+		return
+	}
+
+	if n <= 0 {
+		if x := node.extras.Load(); x != nil {
+			x.childLimit.Store(nil)
+		}
+		return
+	}
+
+	node.ext().childLimit.Store(&childLimit{newSemaphore(int64(n)), block})
+}
+
+// SetFairScheduling enables or disables fair scheduling of the children of
+// ctx blocked on its limit (see SetMaxChildren()). When enabled, blocked
+// registrations are admitted round-robin across tenants instead of in FIFO
+// order, so one tenant fanning out heavily can not starve others sharing the
+// same limit. The tenant of a child is its first tag (see GoTagged() and
+// EnableWaitTagged()). Untagged children share a single tenant.
+//
+// It must be called after SetMaxChildren().
+func SetFairScheduling(ctx Context, enabled bool) {
+	l := nodeOf(ctx).limit()
+	if l == nil {
+		misuse(ctx, "SetFairScheduling() called on context %v without a child limit", ctx)
+		return
+	}
+
+	l.sem.setFair(enabled)
+}
+
+// TryEnableWait is like EnableWait() but it returns ErrTooManyChildren
+// instead of blocking or reporting a misuse if the limit of children of the
+// parent is reached (see SetMaxChildren()). Nothing is registered in that
+// case.
+func TryEnableWait(ctx Context) (Context, error) {
+	return enableWait(ctx, 1, "TryEnableWait", true)
+}
+
+// TryGo is like Go() but it returns ErrTooManyChildren (and does not run fn)
+// if the limit of children of ctx is reached (see SetMaxChildren()).
+func TryGo(ctx Context, fn func(Context) error) error {
+	return goOn(ctx, ctx, fn, nil, true)
+}
+
+func (n *node) limit() *childLimit {
+	if n == nil {
+		return nil
+	}
+
+	x := n.extras.Load()
+	if x == nil {
+		return nil
+	}
+
+	return x.childLimit.Load()
+}
+
+// acquireChildSlots acquires count slots from the limit of the parent of n
+// (which is associated with ctx), if there is one. Blocking acquisitions give
+// up when ctx is done, returning its error.
+func (n *node) acquireChildSlots(ctx Context, count int, try bool) error {
+	l := n.pNode().limit()
+	if l == nil {
+		return nil
+	}
+
+	if try || !l.block || int64(count) > l.sem.size {
+		if !l.sem.tryAcquire(int64(count)) {
+			return ErrTooManyChildren
+		}
+	} else if err := l.sem.acquireAs(ctx, int64(count), n.tenant()); err != nil {
+		return err
+	}
+
+	x := n.ext()
+
+	held := x.heldSlots.Load()
+	if held == nil {
+		x.heldSlots.CompareAndSwap(nil, &heldSlots{})
+		held = x.heldSlots.Load()
+	}
+
+	held.mu.Lock()
+	for i := 0; i < count; i++ {
+		held.sems = append(held.sems, l.sem)
+	}
+	held.mu.Unlock()
+
+	return nil
+}
+
+// tenant returns the tenant n is scheduled as (see SetFairScheduling()).
+func (n *node) tenant() string {
+	tags := n.tagsOf()
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return tags[0]
+}
+
+// releaseChildSlot releases a slot held on a limit of the parent, if any. It
+// is released to the limit it was acquired from, even if the parent limit
+// changed in the meantime.
+func (n *node) releaseChildSlot() {
+	x := n.extras.Load()
+	if x == nil {
+		return
+	}
+
+	held := x.heldSlots.Load()
+	if held == nil {
+		return
+	}
+
+	held.mu.Lock()
+
+	last := len(held.sems) - 1
+	if last < 0 {
+		held.mu.Unlock()
+		return
+	}
+
+	sem := held.sems[last]
+	held.sems[last] = nil
+	held.sems = held.sems[:last]
+
+	held.mu.Unlock()
+
+	sem.release(1)
+}
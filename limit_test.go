@@ -0,0 +1,222 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetMaxChildren(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 2, false)
+
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		if err := TryGo(parent, func(ctx Context) error {
+			<-release
+			return nil
+		}); err != nil {
+			t.Fatalf("Unexpected error %v.", err)
+		}
+	}
+
+	if err := TryGo(parent, func(ctx Context) error { return nil }); err != ErrTooManyChildren {
+		t.Errorf("Expected error to be %v. Got %v.", ErrTooManyChildren, err)
+	}
+	if _, err := TryEnableWait(WithName(parent, "child")); err != ErrTooManyChildren {
+		t.Errorf("Expected error to be %v. Got %v.", ErrTooManyChildren, err)
+	}
+
+	close(release)
+	parent.WaitForChildren()
+
+	child, err := TryEnableWait(WithName(parent, "child"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+	child.Finished()
+}
+
+func TestSetMaxChildren_Block(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 1, true)
+
+	first := EnableWait(WithName(parent, "first"))
+
+	registered := make(chan Context)
+	go func() {
+		registered <- EnableWait(WithName(parent, "second"))
+	}()
+
+	select {
+	case <-registered:
+		t.Fatalf("Expected registration to block.")
+	case <-time.After(1 * time.Millisecond):
+	}
+
+	first.Finished()
+
+	select {
+	case second := <-registered:
+		second.Finished()
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timeout waiting for registration.")
+	}
+
+	parent.WaitForChildren()
+}
+
+func TestSetFairScheduling(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 1, true)
+	SetFairScheduling(parent, true)
+
+	sem := nodeOf(parent).limit().sem
+	queued := func(n int) {
+		for {
+			sem.mu.Lock()
+			l := sem.waiters.Len()
+			sem.mu.Unlock()
+
+			if l == n {
+				return
+			}
+
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+
+	release := make(chan struct{})
+	Go(parent, func(ctx Context) error {
+		<-release
+		return nil
+	})
+
+	var mu sync.Mutex
+	var order []string
+
+	// Blocked registrations are not pending children yet, so
+	// WaitForChildren() can not be used to wait for them.
+	var wg sync.WaitGroup
+
+	submit := func(tenant string) {
+		wg.Add(1)
+		go GoTagged(parent, func(ctx Context) error {
+			mu.Lock()
+			order = append(order, tenant)
+			mu.Unlock()
+			wg.Done()
+			return nil
+		}, tenant)
+	}
+
+	// The noisy tenant submits all its work first.
+	for i := 0; i < 3; i++ {
+		submit("noisy")
+		queued(i + 1)
+	}
+	submit("quiet")
+	queued(4)
+
+	close(release)
+	wg.Wait()
+	parent.WaitForChildren()
+
+	expected := []string{"noisy", "quiet", "noisy", "noisy"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v. Got %v.", expected, order)
+	}
+}
+
+func TestSetMaxChildren_BlockCanceled(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 1, true)
+
+	first := EnableWait(WithName(parent, "first"))
+	defer first.Finished()
+
+	ctx, cancel := WithCancel(parent)
+	cancel()
+
+	// Must not block forever waiting for a slot.
+	if _, err := TryEnableWait(ctx); err != ErrTooManyChildren {
+		t.Errorf("Expected error to be %v. Got %v.", ErrTooManyChildren, err)
+	}
+
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	EnableWait(ctx)
+
+	if err := Misuse(ctx); err == nil || !strings.Contains(err.Error(), Canceled.Error()) {
+		t.Errorf("Expected misuse caused by %v. Got %v.", Canceled, err)
+	}
+}
+
+func TestSetMaxChildren_BlockCanceledFree(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 1, true)
+
+	ctx, cancel := WithCancel(parent)
+	cancel()
+
+	// A free slot is acquired even if ctx is already done.
+	child := EnableWait(ctx)
+	if n := lookup(parent).childrenWg.count(); n != 1 {
+		t.Errorf("Expected 1 pending child. Got %d.", n)
+	}
+
+	child.Finished()
+	parent.WaitForChildren()
+}
+
+func TestSetMaxChildren_Replaced(t *testing.T) {
+	parent := Background()
+	SetMaxChildren(parent, 1, false)
+
+	child := EnableWait(WithName(parent, "child"))
+
+	SetMaxChildren(parent, 1, false)
+
+	// Released to the limit it was acquired from.
+	child.Finished()
+
+	if _, err := TryEnableWait(WithName(parent, "other")); err != nil {
+		t.Errorf("Unexpected error %v.", err)
+	}
+}
+
+func TestSetMaxChildren_RejectedGo(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	parent := Background()
+	SetMaxChildren(parent, 1, false)
+
+	release := make(chan struct{})
+	Go(parent, func(ctx Context) error {
+		<-release
+		return nil
+	})
+
+	ran := false
+	GoTagged(parent, func(ctx Context) error {
+		ran = true
+		return nil
+	}, "rejected")
+
+	close(release)
+	parent.WaitForChildren()
+	WaitForChildrenTagged(parent, "rejected")
+
+	if ran {
+		t.Errorf("Expected rejected function to not run.")
+	}
+
+	errs := Misuse(parent)
+	if errs == nil || strings.Contains(errs.Error(), "Finished()") {
+		t.Errorf("Expected only the rejection to be reported. Got %v.", errs)
+	}
+}
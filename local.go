@@ -0,0 +1,120 @@
+package context
+
+import (
+	"context"
+	"sync"
+)
+
+// locals holds the values set with SetLocal() and their cleanup functions.
+type locals struct {
+	mu sync.Mutex
+
+	values   map[any]any
+	cleanups []func()
+
+	// Set once the context is done and the cleanup functions were called.
+	cleanedUp bool
+
+	// Set once cleanupLocals() is scheduled to run when the context is done.
+	watching bool
+}
+
+func (c *ctxImpl) SetLocal(key, value any, cleanup func()) {
+	c.addLocal(key, value, cleanup, true, true)
+}
+
+func (c *ctxImpl) RegisterCleanup(fn func(err error)) {
+	c.addLocal(nil, nil, func() {
+		fn(context.Cause(c))
+	}, false, false)
+}
+
+// addLocal sets the local value for key (if hasValue is true) and registers
+// cleanup to be called when the context is torn down. If replace is false and
+// key already has a value, nothing changes and the existing value is
+// returned with loaded set to true. Otherwise value is returned.
+func (c *ctxImpl) addLocal(key, value any, cleanup func(), hasValue, replace bool) (actual any, loaded bool) {
+	l := &c.ext().locals
+
+	l.mu.Lock()
+
+	if l.cleanedUp {
+		// Too late. Nothing will ever see the value so just clean it up.
+		l.mu.Unlock()
+
+		if cleanup != nil {
+			cleanup()
+		}
+
+		return value, false
+	}
+
+	if hasValue {
+		if existing, ok := l.values[key]; ok && !replace {
+			l.mu.Unlock()
+			return existing, true
+		}
+
+		if l.values == nil {
+			l.values = make(map[any]any)
+		}
+		l.values[key] = value
+	}
+
+	if cleanup != nil {
+		l.cleanups = append(l.cleanups, cleanup)
+	}
+
+	if !l.watching {
+		l.watching = true
+		// Nothing waits for contexts that are never done, so they can still be
+		// collected.
+		context.AfterFunc(c.Context, c.cleanupLocals)
+	}
+
+	l.mu.Unlock()
+
+	return value, false
+}
+
+func (n *node) Local(key any) (any, bool) {
+	for n != nil {
+		if x := n.extras.Load(); x != nil {
+			x.locals.mu.Lock()
+			value, ok := x.locals.values[key]
+			x.locals.mu.Unlock()
+
+			if ok {
+				return value, true
+			}
+		}
+
+		if n.parent == nil {
+			break
+		}
+
+		n = nodeOf(n.parent)
+	}
+
+	return nil, false
+}
+
+// cleanupLocals waits for all children of the (done) context to finish and
+// then calls all cleanup functions in reverse registration order. It runs in
+// its own goroutine once the context is done (see context.AfterFunc()).
+func (c *ctxImpl) cleanupLocals() {
+	c.childrenWg.Wait()
+
+	l := &c.ext().locals
+
+	l.mu.Lock()
+	cleanups := l.cleanups
+	l.values = nil
+	l.cleanups = nil
+	l.cleanedUp = true
+	l.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
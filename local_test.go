@@ -0,0 +1,125 @@
+package context
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSetLocal(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	cleaned := make(chan string, 2)
+	childFinished := false
+
+	ctx.SetLocal("first", 1, func() {
+		if !childFinished {
+			t.Errorf("Expected cleanup to happen after children finished.")
+		}
+		cleaned <- "first"
+	})
+	ctx.SetLocal("second", 2, func() {
+		cleaned <- "second"
+	})
+
+	child, childCancel := WithCancel(ctx)
+	defer childCancel()
+
+	if value, ok := child.Local("first"); !ok || value != 1 {
+		t.Errorf("Expected value to be 1. Got %v.", value)
+	}
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		childFinished = true
+		ctx.Finished()
+	}(EnableWait(child))
+
+	cancel()
+
+	// Cleanup happens in reverse order.
+	for _, expected := range []string{"second", "first"} {
+		select {
+		case name := <-cleaned:
+			if name != expected {
+				t.Errorf("Expected %q to be cleaned up. Got %q.", expected, name)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Timeout waiting for cleanup.")
+		}
+	}
+}
+
+func TestSetLocal_AfterCleanup(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	done := make(chan struct{})
+	ctx.SetLocal("key", 1, func() { close(done) })
+
+	cancel()
+	<-done
+
+	called := false
+	ctx.SetLocal("key", 2, func() { called = true })
+
+	if !called {
+		t.Errorf("Expected late cleanup function to be called immediately.")
+	}
+	if _, ok := ctx.Local("key"); ok {
+		t.Errorf("Expected value to not be available after cleanup.")
+	}
+}
+
+func TestSetLocal_NeverDone(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		WithName(Background(), "never-done").SetLocal("key", i, func() {})
+	}
+
+	if after := runtime.NumGoroutine(); after >= before+100 {
+		t.Errorf("Expected no goroutine per context that is never done. Got %d goroutines (%d before).", after, before)
+	}
+}
+
+func TestRegisterCleanup(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	cleaned := make(chan int, 3)
+
+	for i := 0; i < 3; i++ {
+		ctx.RegisterCleanup(func(err error) {
+			if err != Canceled {
+				t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+			}
+			cleaned <- i
+		})
+	}
+
+	child := EnableWait(WithName(ctx, "child"))
+
+	cancel()
+
+	select {
+	case i := <-cleaned:
+		t.Errorf("Expected no cleanup before children finished. Got %d.", i)
+	case <-time.After(1 * time.Millisecond):
+	}
+
+	child.Finished()
+
+	for _, expected := range []int{2, 1, 0} {
+		select {
+		case i := <-cleaned:
+			if i != expected {
+				t.Errorf("Expected cleanup %d. Got %d.", expected, i)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Timeout waiting for cleanup.")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package context
+
+import (
+	"io"
+	"sync"
+)
+
+// memoKey is the local key (see SetLocal()) used for values computed with
+// GetOrCompute(), so they do not collide with other local values.
+type memoKey struct {
+	key any
+}
+
+// memo is a value computed (or being computed) with GetOrCompute().
+type memo struct {
+	// Closed once val and err are set.
+	done chan struct{}
+	val  any
+	err  error
+
+	mu       sync.Mutex
+	disposed bool
+}
+
+// GetOrCompute returns the value associated with key in ctx or in the
+// nearest ancestor that has it. If there is none, compute is called with ctx
+// and its result is cached in ctx, so ctx and all its descendants share it.
+// Concurrent callers wait for the same computation. Errors are returned to
+// all callers waiting for the computation but are not cached.
+//
+// Cached values are disposed when ctx is done and all its children finished
+// (see RegisterCleanup()): values implementing io.Closer are closed at that
+// point. Callers using the same key must use the same type T.
+func GetOrCompute[T any](ctx Context, key any, compute func(Context) (T, error)) (T, error) {
+	c := lookup(ctx)
+	if c == nil {
+		return compute(ctx)
+	}
+
+	mk := memoKey{key}
+
+	var m *memo
+	if value, ok := c.Local(mk); ok {
+		m = value.(*memo)
+	} else {
+		m = &memo{
+			done: make(chan struct{}),
+		}
+
+		actual, loaded := c.addLocal(mk, m, m.dispose, true, false)
+		if !loaded {
+			m.compute(c, mk, func(ctx Context) (any, error) {
+				return compute(ctx)
+			})
+		}
+		m = actual.(*memo)
+	}
+
+	<-m.done
+
+	val, _ := m.val.(T)
+	return val, m.err
+}
+
+// compute sets the value of m using fn. Failed computations are removed from
+// c, so later callers retry them.
+func (m *memo) compute(c *ctxImpl, key memoKey, fn func(Context) (any, error)) {
+	val, err := fn(c)
+
+	if err != nil {
+		l := &c.ext().locals
+		l.mu.Lock()
+		if l.values[key] == m {
+			delete(l.values, key)
+		}
+		l.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	m.val, m.err = val, err
+	disposed := m.disposed
+	close(m.done)
+	m.mu.Unlock()
+
+	if disposed {
+		// Computed after c was torn down.
+		m.close()
+	}
+}
+
+// dispose is the cleanup function for m.
+func (m *memo) dispose() {
+	m.mu.Lock()
+	m.disposed = true
+	select {
+	case <-m.done:
+	default:
+		// Still being computed. Closed when done.
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	m.close()
+}
+
+func (m *memo) close() {
+	if closer, ok := m.val.(io.Closer); ok && m.err == nil {
+		closer.Close()
+	}
+}
@@ -0,0 +1,117 @@
+package context
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCloser struct {
+	closed atomic.Bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestGetOrCompute(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	var calls atomic.Int32
+	compute := func(Context) (*testCloser, error) {
+		calls.Add(1)
+		return &testCloser{}, nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]*testCloser, 5)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err := GetOrCompute(WithName(ctx, "child"), "key", compute)
+			if err != nil {
+				t.Errorf("Unexpected error %v.", err)
+			}
+			values[i] = v
+		}()
+	}
+	wg.Wait()
+
+	// Children compute their own values but descendants share them.
+	child := WithName(ctx, "child")
+	v1, _ := GetOrCompute(child, "key", compute)
+	v2, _ := GetOrCompute(WithName(child, "grandchild"), "key", compute)
+	if v1 != v2 {
+		t.Errorf("Expected descendants to share the value.")
+	}
+
+	if n := calls.Load(); n != 6 {
+		t.Errorf("Expected 6 computations. Got %d.", n)
+	}
+
+	cancel()
+
+	for i := 0; i < 100 && !v1.closed.Load(); i++ {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	if !v1.closed.Load() {
+		t.Errorf("Expected value to be closed after the context was done.")
+	}
+}
+
+func TestGetOrCompute_Shared(t *testing.T) {
+	ctx := Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, _ := GetOrCompute(ctx, "key", func(Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			if v != 42 {
+				t.Errorf("Expected 42. Got %d.", v)
+			}
+		}()
+	}
+
+	time.Sleep(1 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 computation. Got %d.", n)
+	}
+}
+
+func TestGetOrCompute_Error(t *testing.T) {
+	ctx := Background()
+
+	errCompute := errors.New("compute failed")
+	if _, err := GetOrCompute(ctx, "key", func(Context) (int, error) {
+		return 0, errCompute
+	}); err != errCompute {
+		t.Errorf("Expected error %v. Got %v.", errCompute, err)
+	}
+
+	// Errors are not cached.
+	v, err := GetOrCompute(ctx, "key", func(Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("Expected 42 and no error. Got %d and %v.", v, err)
+	}
+}
@@ -0,0 +1,147 @@
+package context
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// mergedCtx is the standard library side of a context with multiple parents.
+type mergedCtx struct {
+	// Derived from the primary parent. Canceled when any parent is done.
+	context.Context
+
+	others []Context
+}
+
+// valueAllKey is used to collect the values associated with a key in all
+// parents of merged contexts.
+type valueAllKey struct {
+	key any
+}
+
+func (m *mergedCtx) Value(key any) any {
+	if k, ok := key.(valueAllKey); ok {
+		values := ValueAll(m.Context, k.key)
+		for _, other := range m.others {
+			values = append(values, ValueAll(other, k.key)...)
+		}
+
+		return values
+	}
+
+	value := m.Context.Value(key)
+	if value != nil || key == (nodeKey{}) {
+		// The wait state always comes from the primary parent.
+		return value
+	}
+
+	for _, other := range m.others {
+		if value := other.Value(key); value != nil {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// sameValue returns true if a and b are the same value. Values that can not
+// be compared with == (slices, maps and functions) are the same if they refer
+// to the same data.
+func sameValue(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+
+	switch va.Kind() {
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	case reflect.Map, reflect.Func:
+		return va.Pointer() == vb.Pointer()
+	}
+
+	return va.Comparable() && a == b
+}
+
+func (m *mergedCtx) String() string {
+	names := make([]string, len(m.others))
+	for i, other := range m.others {
+		names[i] = contextName(other)
+	}
+
+	return contextName(m.Context) + ".Merge(" + strings.Join(names, ", ") + ")"
+}
+
+// Merge returns a copy of primary that is also done when any of the other
+// given contexts is done. Its deadline is the earliest deadline among all of
+// them, tracked with the clock of primary (see WithClock()). Values are looked up in primary first and then in each of the other
+// contexts, in order (see also ValueAll()). The wait state comes exclusively
+// from primary, so it should be used to report completion and wait for
+// children.
+func Merge(primary Context, others ...Context) (Context, CancelFunc) {
+	std := stdContext(primary)
+
+	deadline, ok := primary.Deadline()
+	for _, other := range others {
+		if d, otherOk := other.Deadline(); otherOk && (!ok || d.Before(deadline)) {
+			deadline, ok = d, true
+		}
+	}
+
+	// The deadline is tracked with the clock of primary, like the ones of
+	// contexts derived from it (see WithClock()).
+	cancelDeadline := func() {}
+	if d, primaryOk := primary.Deadline(); ok && (!primaryOk || deadline.Before(d)) {
+		std, cancelDeadline = withDeadline(std, deadline, time.Time{}, nodeOf(primary).getClock(), false)
+	}
+
+	std, cancel := context.WithCancelCause(std)
+
+	stops := make([]func() bool, len(others))
+	for i, other := range others {
+		stops[i] = context.AfterFunc(other, func() {
+			cancel(nil)
+		})
+	}
+
+	c := newCtx(&mergedCtx{std, others}, primary)
+	c.cancel = cancel
+	c.track()
+
+	derived(primary, c, KindCancel)
+
+	return c, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		c.cancelWith(nil)
+		cancelDeadline()
+	}
+}
+
+// ValueAll returns all values associated with key. For contexts with
+// multiple parents (see Merge()), it returns the values found in each of
+// them, in lookup order, preceded by the values that shadow them in layers
+// added after the merge (nearest first). Otherwise, it returns the single
+// value found (if any).
+func ValueAll(ctx context.Context, key any) []any {
+	if values, ok := ctx.Value(valueAllKey{key}).([]any); ok {
+		// Layers of this package contribute their values on the way down
+		// (see valuesCtx), but others (like the ones added by the standard
+		// library WithValue()) just pass the lookup through, so the value
+		// that shadows all others might be missing.
+		if value := ctx.Value(key); value != nil && (len(values) == 0 || !sameValue(value, values[0])) {
+			values = append([]any{value}, values...)
+		}
+
+		return values
+	}
+
+	if value := ctx.Value(key); value != nil {
+		return []any{value}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mergeKey string
+
+func TestMerge(t *testing.T) {
+	server, cancelServer := WithCancel(Background())
+	defer cancelServer()
+
+	request, cancelRequest := WithTimeout(Background(), 1*time.Hour)
+	defer cancelRequest()
+
+	serverValues := Adopt(stdcontext.WithValue(server, mergeKey("key"), "server"))
+	requestValues := Adopt(stdcontext.WithValue(
+		stdcontext.WithValue(request, mergeKey("key"), "request"),
+		mergeKey("request-only"), "request-only"))
+
+	ctx, cancel := Merge(serverValues, requestValues)
+	defer cancel()
+
+	if value := ctx.Value(mergeKey("key")); value != "server" {
+		t.Errorf("Expected value to be %q. Got %v.", "server", value)
+	}
+	if value := ctx.Value(mergeKey("request-only")); value != "request-only" {
+		t.Errorf("Expected value to be %q. Got %v.", "request-only", value)
+	}
+
+	values := ValueAll(ctx, mergeKey("key"))
+	if len(values) != 2 || values[0] != "server" || values[1] != "request" {
+		t.Errorf("Expected values to be [server request]. Got %v.", values)
+	}
+
+	requestDeadline, _ := request.Deadline()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(requestDeadline) {
+		t.Errorf("Expected deadline to be %v. Got %v.", requestDeadline, deadline)
+	}
+
+	if ctx.Parent() != serverValues {
+		t.Errorf("Expected parent to be the primary context.")
+	}
+
+	cancelRequest()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Expected merged context to be done.")
+	}
+
+	if server.Err() != nil {
+		t.Errorf("Expected primary context to not be done.")
+	}
+}
+
+func TestMerge_CancelChildren(t *testing.T) {
+	parent := Background()
+
+	other, cancelOther := WithCancel(Background())
+	defer cancelOther()
+
+	ctx, cancel := Merge(WithName(parent, "primary"), other)
+	defer cancel()
+
+	errStop := errors.New("stop")
+	parent.CancelChildren(errStop)
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected merged context to be canceled. Got %v.", ctx.Err())
+	}
+	if cause := stdcontext.Cause(ctx); cause != errStop {
+		t.Errorf("Expected cause to be %v. Got %v.", errStop, cause)
+	}
+}
+
+func TestMerge_Clock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	clock := &manualClock{now: now}
+	primary := WithClock(Background(), clock)
+
+	// Its own deadline never fires, so only the merged one can.
+	other, cancelOther := WithTimeout(WithClock(Background(), &manualClock{now: now}), time.Hour)
+	defer cancelOther()
+
+	ctx, cancel := Merge(primary, other)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		t.Fatalf("Expected no error. Got %v.", ctx.Err())
+	}
+
+	clock.fire()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Expected merged context to be done.")
+	}
+
+	if ctx.Err() != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, ctx.Err())
+	}
+	if other.Err() != nil {
+		t.Errorf("Expected other context to not be done.")
+	}
+}
+
+func TestValueAll_Shadowed(t *testing.T) {
+	primary := WithValues(Background(), mergeKey("key"), "primary")
+	other := WithValues(Background(), mergeKey("key"), "other")
+
+	merged, cancel := Merge(primary, other)
+	defer cancel()
+
+	values := ValueAll(WithValues(WithValues(merged, mergeKey("key"), "inner"), mergeKey("key"), "outer"), mergeKey("key"))
+	if len(values) != 4 || values[0] != "outer" || values[1] != "inner" || values[2] != "primary" || values[3] != "other" {
+		t.Errorf("Expected values to be [outer inner primary other]. Got %v.", values)
+	}
+
+	values = ValueAll(stdcontext.WithValue(merged, mergeKey("key"), "std"), mergeKey("key"))
+	if len(values) != 3 || values[0] != "std" || values[1] != "primary" {
+		t.Errorf("Expected values to be [std primary other]. Got %v.", values)
+	}
+
+	// Values of other keys do not shadow anything.
+	values = ValueAll(WithValues(merged, mergeKey("unrelated"), "value"), mergeKey("key"))
+	if len(values) != 2 || values[0] != "primary" || values[1] != "other" {
+		t.Errorf("Expected values to be [primary other]. Got %v.", values)
+	}
+}
+
+func TestValueAll(t *testing.T) {
+	ctx := stdcontext.WithValue(Background(), mergeKey("key"), "value")
+
+	values := ValueAll(ctx, mergeKey("key"))
+	if len(values) != 1 || values[0] != "value" {
+		t.Errorf("Expected values to be [value]. Got %v.", values)
+	}
+	if values := ValueAll(ctx, mergeKey("missing")); len(values) != 0 {
+		t.Errorf("Expected no values. Got %v.", values)
+	}
+}
@@ -0,0 +1,134 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// ErrMisuse is wrapped by all errors describing a misuse of this package API
+// (see SetMisusePolicy()).
+var ErrMisuse = errors.New("context: misuse")
+
+// MisusePolicy determines what happens when this package API is misused. The
+// misuses covered are calling Finished() more times than EnableWait(), calling
+// the EnableWait() family on a root context (or on one that can not be
+// registered, like when Go() is rejected by SetMaxChildren()), calling
+// EnableWaitOn() with a context that is not an ancestor, calling the wait
+// configuration functions (like SetWaitCapacity()) on a context without wait
+// support and deriving a context without a ValueRequired value. Other
+// misuses are only reported if enabled with SetMisuseChecks().
+type MisusePolicy int32
+
+const (
+	// MisusePanic panics on misuse. This is the default.
+	MisusePanic MisusePolicy = iota
+
+	// MisuseError ignores the misused call and records an error that can be
+	// retrieved with Misuse().
+	MisuseError
+
+	// MisuseLog ignores the misused call and logs an error with the
+	// standard library log package.
+	MisuseLog
+)
+
+var misusePolicy atomic.Int32
+
+// SetMisusePolicy sets the policy applied on misuse for all contexts. This
+// allows libraries to choose robustness over crashing in production.
+func SetMisusePolicy(policy MisusePolicy) {
+	misusePolicy.Store(int32(policy))
+}
+
+// MisuseChecks selects misuses that are not reported by default, as what
+// they detect is legitimate in many programs (see SetMisuseChecks()).
+type MisuseChecks uint32
+
+const (
+	// CheckWaitNonRoot reports calls to WaitForChildren() (and its
+	// variants, like WaitForChildrenRespectingDeadline()) on contexts that
+	// are not roots (see CloneDetachedWait()), for programs where only the
+	// owner of a tree is expected to wait for it.
+	CheckWaitNonRoot MisuseChecks = 1 << iota
+
+	// CheckReuseAfterDone reports registering work (with the EnableWait()
+	// family, Go() and friends) on a context that is already done. When not
+	// enabled, such registrations are allowed so the work can still report
+	// completion.
+	CheckReuseAfterDone
+)
+
+var misuseChecks atomic.Uint32
+
+// SetMisuseChecks enables the given optional misuse checks for all contexts,
+// disabling any others. Misuses found are handled according to the misuse
+// policy (see SetMisusePolicy()). Under non-panicking policies, the misused
+// call is ignored: waits return right away and registrations are not made.
+func SetMisuseChecks(checks MisuseChecks) {
+	misuseChecks.Store(uint32(checks))
+}
+
+// checkEnabled returns true if the given optional misuse check is enabled.
+func checkEnabled(check MisuseChecks) bool {
+	return MisuseChecks(misuseChecks.Load())&check != 0
+}
+
+// checkWait reports a misuse if ctx is not a root and CheckWaitNonRoot is
+// enabled. It returns false if the wait must be skipped.
+func checkWait(ctx Context, caller string) bool {
+	if !checkEnabled(CheckWaitNonRoot) {
+		return true
+	}
+
+	if n := nodeOf(ctx); n == nil || n.pNode() == nil {
+		return true
+	}
+
+	misuse(ctx, "%s() called on non-root context %v", caller, ctx)
+
+	return false
+}
+
+// Misuse returns all the misuse errors recorded for ctx (see MisuseError)
+// joined together or nil if there are none.
+func Misuse(ctx Context) error {
+	n := nodeOf(ctx)
+	if n == nil {
+		return nil
+	}
+
+	x := n.extras.Load()
+	if x == nil {
+		return nil
+	}
+
+	x.errsMu.Lock()
+	defer x.errsMu.Unlock()
+
+	return errors.Join(x.misuses...)
+}
+
+// misuse applies the current misuse policy for a misuse involving ctx. If the
+// policy does not panic, it returns the misuse error so callers can skip the
+// misused operation.
+func misuse(ctx Context, format string, args ...any) error {
+	err := fmt.Errorf("%w: %s", ErrMisuse, fmt.Sprintf(format, args...))
+
+	switch MisusePolicy(misusePolicy.Load()) {
+	case MisuseError:
+		if n := nodeOf(ctx); n != nil {
+			x := n.ext()
+			x.errsMu.Lock()
+			x.misuses = append(x.misuses, err)
+			x.errsMu.Unlock()
+		}
+	case MisuseLog:
+		log.Print(err)
+	default:
+		panic(err)
+	}
+
+	return err
+}
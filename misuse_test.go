@@ -0,0 +1,128 @@
+package context
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMisusePolicy_Panic(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrMisuse) {
+			t.Errorf("Expected panic with %v. Got %v.", ErrMisuse, err)
+		}
+	}()
+
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	ctx.Finished()
+}
+
+func TestMisusePolicy_Error(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	root := Background()
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	EnableWait(ctx)
+	ctx.Finished()
+	ctx.Finished()
+
+	EnableWait(root)
+
+	if err := Misuse(ctx); !errors.Is(err, ErrMisuse) {
+		t.Errorf("Expected error to be %v. Got %v.", ErrMisuse, err)
+	}
+	if err := Misuse(root); !errors.Is(err, ErrMisuse) {
+		t.Errorf("Expected error to be %v. Got %v.", ErrMisuse, err)
+	}
+	if n := root.WaitStats().ChildrenFinished; n != 1 {
+		t.Errorf("Expected 1 finished child. Got %d.", n)
+	}
+
+	// Not blocked by the ignored calls.
+	root.WaitForChildren()
+}
+
+func TestSetMisuseChecks_WaitNonRoot(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	root := Background()
+	ctx := WithName(root, "non-root")
+
+	// Not a misuse by default.
+	ctx.WaitForChildren()
+	if err := Misuse(ctx); err != nil {
+		t.Errorf("Unexpected misuse %v.", err)
+	}
+
+	SetMisuseChecks(CheckWaitNonRoot)
+	defer SetMisuseChecks(0)
+
+	child := EnableWait(WithName(ctx, "child"))
+	defer child.Finished()
+
+	// Ignored, so it does not block.
+	ctx.WaitForChildren()
+	if err := WaitForChildrenRespectingDeadline(ctx); err != nil {
+		t.Errorf("Unexpected error %v.", err)
+	}
+
+	if err := Misuse(ctx); !errors.Is(err, ErrMisuse) {
+		t.Errorf("Expected error to be %v. Got %v.", ErrMisuse, err)
+	}
+
+	root.WaitForChildren()
+	if err := Misuse(root); err != nil {
+		t.Errorf("Unexpected misuse %v.", err)
+	}
+
+	// Waits done by this package itself are not affected.
+	results, err := FanOut(ctx, []int{1, 2}, 0, func(ctx Context, n int) (int, error) {
+		return n, nil
+	})
+	if err != nil || len(results) != 2 {
+		t.Errorf("Expected 2 results. Got %v (error %v).", results, err)
+	}
+}
+
+func TestSetMisuseChecks_ReuseAfterDone(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	cancel()
+
+	// Allowed by default.
+	EnableWait(ctx).Finished()
+
+	SetMisuseChecks(CheckReuseAfterDone)
+	defer SetMisuseChecks(0)
+
+	EnableWait(ctx)
+
+	ran := false
+	Go(ctx, func(ctx Context) error {
+		ran = true
+		return nil
+	})
+
+	if err := Misuse(ctx); !errors.Is(err, ErrMisuse) {
+		t.Errorf("Expected error to be %v. Got %v.", ErrMisuse, err)
+	}
+
+	// Nothing was registered.
+	parent.WaitForChildren()
+	ctx.WaitForChildren()
+
+	if ran {
+		t.Errorf("Expected function to not run.")
+	}
+}
@@ -0,0 +1,174 @@
+package context
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// onCancel holds the functions registered with OnCancel().
+type onCancel struct {
+	mu sync.Mutex
+
+	fns []func(cause error)
+
+	// Set once the functions were called.
+	fired bool
+
+	// Set once a fallback for cancellations that do not go through
+	// cancelWith() is registered.
+	watching bool
+}
+
+func (c *ctxImpl) OnCancel(fn func(cause error)) {
+	o := &c.ext().onCancel
+
+	o.mu.Lock()
+
+	if o.fired || c.Err() != nil {
+		o.mu.Unlock()
+
+		fn(context.Cause(c))
+
+		return
+	}
+
+	o.fns = append(o.fns, fn)
+
+	if !o.watching {
+		o.watching = true
+		context.AfterFunc(c.Context, func() {
+			c.fireOnCancel(nil)
+		})
+	}
+
+	o.mu.Unlock()
+}
+
+// cancelWith calls the functions registered with OnCancel() and then cancels
+// the context with the given cause. It must only be called for cancelable
+// contexts.
+func (c *ctxImpl) cancelWith(cause error) {
+	if timingsEnabled() {
+		c.canceledAt.CompareAndSwap(0, time.Now().UnixNano())
+	}
+	c.fireOnCancel(cause)
+	c.cancel(cause)
+}
+
+// fireOnCancel calls the functions registered with OnCancel(), in
+// registration order, if they were not called yet.
+func (c *ctxImpl) fireOnCancel(cause error) {
+	x := c.extras.Load()
+	if x == nil {
+		// Nothing registered.
+		return
+	}
+
+	o := &x.onCancel
+
+	o.mu.Lock()
+
+	if o.fired {
+		o.mu.Unlock()
+		return
+	}
+
+	o.fired = true
+	fns := o.fns
+	o.fns = nil
+
+	o.mu.Unlock()
+
+	if len(fns) == 0 {
+		return
+	}
+
+	if err := context.Cause(c.Context); err != nil {
+		// Already canceled. Report the actual cause.
+		cause = err
+	} else if cause == nil {
+		cause = Canceled
+	}
+
+	for _, fn := range fns {
+		fn(cause)
+	}
+}
+
+// deferred is a function registered with Defer() that did not finish yet.
+type deferred struct {
+	// Set once the registration was released, either by the function
+	// finishing or by the parent abandoning it.
+	released atomic.Bool
+}
+
+// defers holds the pending Defer() registrations of a context.
+type defers struct {
+	mu sync.Mutex
+
+	pending map[*deferred]struct{}
+}
+
+func (c *ctxImpl) Defer(fn func()) {
+	d := &deferred{}
+
+	x := c.ext()
+	x.defers.mu.Lock()
+	if x.defers.pending == nil {
+		x.defers.pending = make(map[*deferred]struct{})
+	}
+	x.defers.pending[d] = struct{}{}
+	x.defers.mu.Unlock()
+
+	c.childrenWg.Add(1)
+
+	context.AfterFunc(c.Context, func() {
+		defer c.releaseDefer(d)
+		fn()
+	})
+}
+
+// releaseDefer releases the registration of d, if it was not released yet.
+// It returns true if it was.
+func (c *ctxImpl) releaseDefer(d *deferred) bool {
+	if !d.released.CompareAndSwap(false, true) {
+		return false
+	}
+
+	x := c.extras.Load()
+	x.defers.mu.Lock()
+	delete(x.defers.pending, d)
+	x.defers.mu.Unlock()
+
+	c.childrenWg.Done()
+
+	return true
+}
+
+// abandonDefers releases all pending Defer() registrations of c, so the
+// functions do not delay waits on c anymore when they eventually run. It
+// returns the number of registrations released.
+func (c *ctxImpl) abandonDefers() int {
+	x := c.extras.Load()
+	if x == nil {
+		return 0
+	}
+
+	x.defers.mu.Lock()
+	pending := make([]*deferred, 0, len(x.defers.pending))
+	for d := range x.defers.pending {
+		pending = append(pending, d)
+	}
+	x.defers.mu.Unlock()
+
+	released := 0
+	for _, d := range pending {
+		if c.releaseDefer(d) {
+			released++
+		}
+	}
+
+	return released
+}
@@ -0,0 +1,122 @@
+package context
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOnCancel(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	child := EnableWait(WithName(ctx, "child"))
+
+	var calls []error
+	ctx.OnCancel(func(cause error) {
+		if ctx.Err() != nil {
+			t.Errorf("Expected OnCancel to be called before Done() is closed.")
+		}
+		calls = append(calls, cause)
+	})
+
+	// The child is still pending so this would block if OnCancel waited on
+	// children.
+	cancel()
+
+	if len(calls) != 1 || calls[0] != Canceled {
+		t.Errorf("Expected a single call with %v. Got %v.", Canceled, calls)
+	}
+
+	cancel()
+
+	if len(calls) != 1 {
+		t.Errorf("Expected a single call. Got %d.", len(calls))
+	}
+
+	ctx.OnCancel(func(cause error) {
+		calls = append(calls, cause)
+	})
+
+	if len(calls) != 2 {
+		t.Errorf("Expected immediate call for canceled context. Got %d calls.", len(calls))
+	}
+
+	child.Finished()
+}
+
+func TestOnCancel_CancelChildren(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	errAbort := errors.New("abort")
+
+	var got error
+	ctx.OnCancel(func(cause error) {
+		got = cause
+	})
+
+	parent.CancelChildren(errAbort)
+
+	if got != errAbort {
+		t.Errorf("Expected cause to be %v. Got %v.", errAbort, got)
+	}
+}
+
+func TestOnCancel_Deadline(t *testing.T) {
+	ctx, cancel := WithTimeout(Background(), 1*time.Millisecond)
+	defer cancel()
+
+	called := make(chan error, 1)
+	ctx.OnCancel(func(cause error) {
+		called <- cause
+	})
+
+	select {
+	case cause := <-called:
+		if cause != DeadlineExceeded {
+			t.Errorf("Expected cause to be %v. Got %v.", DeadlineExceeded, cause)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timeout waiting for OnCancel.")
+	}
+}
+
+func TestDefer(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	ran := false
+	ctx.Defer(func() {
+		if ctx.Err() == nil {
+			t.Errorf("Expected deferred function to run after cancellation.")
+		}
+		ran = true
+	})
+
+	waited := make(chan struct{})
+	go func() {
+		ctx.WaitForChildren()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatalf("Expected wait to block until deferred function ran.")
+	case <-time.After(1 * time.Millisecond):
+	}
+
+	cancel()
+
+	select {
+	case <-waited:
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Timeout waiting for deferred function.")
+	}
+
+	if !ran {
+		t.Errorf("Expected deferred function to run.")
+	}
+}
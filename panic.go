@@ -0,0 +1,57 @@
+package context
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error reported for managed children that panicked.
+type PanicError struct {
+	// Value passed to panic().
+	Value any
+
+	// Stack of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the value passed to panic() if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// runRecovered calls fn with ctx and returns the error it returned or a
+// *PanicError if it panicked.
+func runRecovered(ctx Context, fn func(Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{r, debug.Stack()}
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// WithCancelOnPanic returns a copy of parent that is canceled, with a
+// *PanicError cause, when any managed goroutine (launched through Go() or
+// any of its variations, Pool, FanOut(), FanIn(), Pipeline, AdaptGroup() or
+// Do()) with it or with any context derived from it panics. The panic is
+// recovered and also reported as the error of the panicking child (see
+// FinishedErr()). This makes sibling workers stop promptly instead of running
+// against a half-failed operation.
+//
+// Without it, panics in managed goroutines are not recovered.
+func WithCancelOnPanic(parent Context) (Context, CancelFunc) {
+	c, cancel := WithCancel(parent)
+
+	impl := lookup(c)
+	impl.setInherited(func(i *inherited) {
+		i.panicCancel = impl
+	})
+
+	return c, cancel
+}
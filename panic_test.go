@@ -0,0 +1,120 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicError(t *testing.T) {
+	errCause := errors.New("cause")
+
+	err := runRecovered(Background(), func(ctx Context) error {
+		panic(errCause)
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a PanicError. Got %v.", err)
+	}
+	if !errors.Is(err, errCause) {
+		t.Errorf("Expected error to wrap %v.", errCause)
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestPanicError") {
+		t.Errorf("Expected stack to include the panicking function.")
+	}
+}
+
+func TestWithCancelOnPanic(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancelOnPanic(parent)
+	defer cancel()
+
+	siblingStopped := make(chan struct{})
+	Go(ctx, func(ctx Context) error {
+		<-ctx.Done()
+		close(siblingStopped)
+		return nil
+	})
+
+	nested := WithName(ctx, "nested")
+	Go(nested, func(ctx Context) error {
+		panic("boom")
+	})
+
+	<-siblingStopped
+
+	var panicErr *PanicError
+	if !errors.As(stdcontext.Cause(ctx), &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected cause to be a PanicError. Got %v.", stdcontext.Cause(ctx))
+	}
+
+	ctx.WaitForChildren()
+	nested.WaitForChildren()
+
+	if !errors.As(nested.ChildrenErr(), &panicErr) {
+		t.Errorf("Expected child error to be a PanicError. Got %v.", nested.ChildrenErr())
+	}
+
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+}
+
+func TestWithCancelOnPanic_Managed(t *testing.T) {
+	tests := map[string]func(ctx Context){
+		"Pool": func(ctx Context) {
+			p := NewPool(ctx, 1)
+			p.Submit(func(Context) error {
+				panic("boom")
+			})
+			p.Close()
+		},
+		"FanOut": func(ctx Context) {
+			FanOut(ctx, []int{1}, 1, func(Context, int) (int, error) {
+				panic("boom")
+			})
+		},
+		"Pipeline": func(ctx Context) {
+			out := NewPipeline(ctx).Stage("panic", func(Context, <-chan any, chan<- any) error {
+				panic("boom")
+			}).Run(nil)
+			<-out
+		},
+		"AdaptGroup": func(ctx Context) {
+			g := &testGroup{}
+			AdaptGroup(ctx, g).Go(func() error {
+				panic("boom")
+			})
+			g.wg.Wait()
+		},
+		"Do": func(ctx Context) {
+			Do(ctx, "key", func(Context) (int, error) {
+				panic("boom")
+			})
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := WithCancelOnPanic(Background())
+			defer cancel()
+
+			run(ctx)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(1 * time.Second):
+				t.Fatalf("Expected context to be canceled.")
+			}
+
+			var panicErr *PanicError
+			if !errors.As(stdcontext.Cause(ctx), &panicErr) || panicErr.Value != "boom" {
+				t.Errorf("Expected cause to be a PanicError. Got %v.", stdcontext.Cause(ctx))
+			}
+		})
+	}
+}
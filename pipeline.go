@@ -0,0 +1,93 @@
+package context
+
+// Stage is a single step of a Pipeline. It must read items from in until it
+// is closed (or ctx is done), write results to out and return. The out
+// channel is closed by the Pipeline when the Stage returns, so the next Stage
+// sees its input closed. Stages must also return promptly when ctx is done
+// (including when blocked writing to out).
+type Stage func(ctx Context, in <-chan any, out chan<- any) error
+
+// Pipeline connects a sequence of Stages through channels. Each Stage runs in
+// its own goroutine with its own wait-enabled child of the Pipeline Context,
+// so a multi-stage job can be torn down by canceling that Context and calling
+// WaitForChildren() on it. Errors returned by Stages are reported with
+// FinishedErr() and can be retrieved with ChildrenErr().
+type Pipeline struct {
+	ctx Context
+
+	names  []string
+	stages []Stage
+}
+
+// NewPipeline returns a new empty Pipeline driven by the given Context.
+func NewPipeline(ctx Context) *Pipeline {
+	return &Pipeline{
+		ctx: ctx,
+	}
+}
+
+// Stage appends a named Stage to the Pipeline. It returns the Pipeline so
+// calls can be chained.
+func (p *Pipeline) Stage(name string, stage Stage) *Pipeline {
+	p.names = append(p.names, name)
+	p.stages = append(p.stages, stage)
+
+	return p
+}
+
+// Run starts all Stages, feeding source to the first one, and returns the
+// output channel of the last one. The returned channel is closed when the
+// last Stage returns.
+func (p *Pipeline) Run(source <-chan any) <-chan any {
+	in := source
+
+	for i, stage := range p.stages {
+		out := make(chan any)
+
+		go func(ctx Context, stage Stage, in <-chan any, out chan any) {
+			untrack := trackGoroutine(ctx)
+
+			err := runChild(ctx, func(ctx Context) error {
+				return stage(ctx, in, out)
+			})
+			close(out)
+			untrack()
+			ctx.FinishedErr(err)
+		}(EnableWait(WithName(p.ctx, p.names[i])), stage, in, out)
+
+		in = out
+	}
+
+	return in
+}
+
+// Map returns a Stage that calls fn for every item read from its input and
+// writes the result to its output. It stops at the first error returned by
+// fn.
+func Map(fn func(ctx Context, item any) (any, error)) Stage {
+	return func(ctx Context, in <-chan any, out chan<- any) error {
+		for {
+			var item any
+			select {
+			case i, ok := <-in:
+				if !ok {
+					return nil
+				}
+				item = i
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			result, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package context
+
+import (
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	source := make(chan any)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			source <- i
+		}
+		close(source)
+	}()
+
+	out := NewPipeline(ctx).
+		Stage("double", Map(func(ctx Context, item any) (any, error) {
+			return item.(int) * 2, nil
+		})).
+		Stage("increment", Map(func(ctx Context, item any) (any, error) {
+			return item.(int) + 1, nil
+		})).
+		Run(source)
+
+	var results []int
+	for result := range out {
+		results = append(results, result.(int))
+	}
+
+	ctx.WaitForChildren()
+
+	if len(results) != 3 || results[0] != 3 || results[1] != 5 || results[2] != 7 {
+		t.Errorf("Expected results to be [3 5 7]. Got %v.", results)
+	}
+	if err := ctx.ChildrenErr(); err != nil {
+		t.Errorf("Unexpected error %v.", err)
+	}
+}
+
+func TestPipeline_Canceled(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+
+	// Source is never closed.
+	source := make(chan any)
+
+	out := NewPipeline(ctx).
+		Stage("first", Map(func(ctx Context, item any) (any, error) {
+			return item, nil
+		})).
+		Stage("second", Map(func(ctx Context, item any) (any, error) {
+			return item, nil
+		})).
+		Run(source)
+
+	source <- 1
+
+	cancel()
+	ctx.WaitForChildren()
+
+	// Output must be closed (possibly after the item in flight).
+	for range out {
+	}
+
+	if err := ctx.ChildrenErr(); !IsCanceled(err) || !IsChildFailure(err) {
+		t.Errorf("Expected canceled child failure. Got %v.", err)
+	}
+}
@@ -0,0 +1,115 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolClosed is returned when submitting work to a closed Pool.
+var ErrPoolClosed = errors.New("context: pool closed")
+
+// Pool is a fixed size pool of workers driven by a Context. Each worker is a
+// wait-enabled child of the Context the Pool was created with, so calling
+// WaitForChildren() on it waits for the Pool to finish all its work. Errors
+// returned by submitted functions are reported with FinishedErr() and can be
+// retrieved with ChildrenErr() on that same Context.
+//
+// Workers stop when the Pool is closed and all submitted work is done or
+// when the Context is done (in which case queued work is discarded).
+type Pool struct {
+	ctx Context
+
+	tasks chan func(Context) error
+
+	mu         sync.Mutex
+	closed     bool
+	submitters sync.WaitGroup
+}
+
+// NewPool creates a new Pool with the given number of workers.
+func NewPool(ctx Context, size int) *Pool {
+	if size <= 0 {
+		panic("tried to create a pool with a non-positive size")
+	}
+
+	p := &Pool{
+		ctx:   ctx,
+		tasks: make(chan func(Context) error, size),
+	}
+
+	for i := 0; i < size; i++ {
+		worker, cancel := withCancelName(ctx, fmt.Sprintf("pool-worker-%d", i))
+		go p.worker(EnableWait(worker), cancel)
+	}
+
+	return p
+}
+
+// Submit queues fn to be run by one of the Pool workers. It blocks if the
+// queue is full. It returns ErrPoolClosed if the Pool was closed or the
+// Context error if it is done before fn could be queued.
+func (p *Pool) Submit(fn func(Context) error) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	p.submitters.Add(1)
+	p.mu.Unlock()
+
+	defer p.submitters.Done()
+
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case p.tasks <- fn:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Close stops accepting new work. Work that was already submitted is still
+// run. Use WaitForChildren() on the Pool Context to wait for it to finish.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	// Wait for any in-flight submissions before closing the queue.
+	p.submitters.Wait()
+	close(p.tasks)
+}
+
+func (p *Pool) worker(ctx Context, cancel CancelFunc) {
+	untrack := trackGoroutine(ctx)
+
+	var errs []error
+	defer func() {
+		cancel()
+		untrack()
+		ctx.FinishedErr(errors.Join(errs...))
+	}()
+
+	for {
+		select {
+		case fn, ok := <-p.tasks:
+			if !ok {
+				return
+			}
+
+			if err := runChild(ctx, fn); err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
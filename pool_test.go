@@ -0,0 +1,82 @@
+package context
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	p := NewPool(ctx, 3)
+
+	var count atomic.Int32
+	errFailed := errors.New("failed")
+
+	for i := 0; i < 10; i++ {
+		i := i
+		err := p.Submit(func(ctx Context) error {
+			count.Add(1)
+			if i == 5 {
+				return errFailed
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error %v.", err)
+		}
+	}
+
+	p.Close()
+
+	if err := p.Submit(func(Context) error { return nil }); err != ErrPoolClosed {
+		t.Errorf("Expected error to be %v. Got %v.", ErrPoolClosed, err)
+	}
+
+	ctx.WaitForChildren()
+
+	if n := count.Load(); n != 10 {
+		t.Errorf("Expected 10 functions to run. Got %d.", n)
+	}
+	if err := ctx.ChildrenErr(); !errors.Is(err, errFailed) {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+}
+
+func TestPool_Canceled(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	p := NewPool(ctx, 1)
+
+	cancel()
+
+	// Workers stop even without closing the pool.
+	ctx.WaitForChildren()
+
+	if err := p.Submit(func(Context) error { return nil }); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+}
+
+func TestPool_CancelChildren(t *testing.T) {
+	ctx, cancel := WithCancel(Background())
+	defer cancel()
+
+	NewPool(ctx, 2)
+
+	ctx.CancelChildren(errors.New("wave aborted"))
+
+	// Workers stop even without closing the pool or canceling ctx.
+	ctx.WaitForChildren()
+
+	if ctx.Err() != nil {
+		t.Errorf("Expected pool context not to be canceled. Got %v.", ctx.Err())
+	}
+}
@@ -0,0 +1,32 @@
+package context
+
+import (
+	"runtime/pprof"
+)
+
+// WithPprofLabels returns a copy of parent with the given profiler labels
+// (as key/value pairs, see pprof.Labels()) added to it. Goroutines launched
+// through Go() with the returned Context (or any Context derived from it) get
+// the labels applied, so CPU profiles attribute samples to the logical task
+// that spawned them.
+func WithPprofLabels(parent Context, labels ...string) Context {
+	c := newCtx(pprof.WithLabels(stdContext(parent), pprof.Labels(labels...)), parent)
+
+	derived(parent, c, KindValue)
+
+	return c
+}
+
+// applyPprofLabels sets the labels carried by ctx (if any) on the current
+// goroutine.
+func applyPprofLabels(ctx Context) {
+	found := false
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		found = true
+		return false
+	})
+
+	if found {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
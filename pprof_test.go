@@ -0,0 +1,22 @@
+package context
+
+import (
+	"runtime/pprof"
+	"testing"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	parent := Background()
+
+	ctx := WithPprofLabels(parent, "task", "worker")
+
+	Go(ctx, func(ctx Context) error {
+		if value, ok := pprof.Label(ctx, "task"); !ok || value != "worker" {
+			t.Errorf("Expected label to be %q. Got %q.", "worker", value)
+		}
+
+		return nil
+	})
+
+	ctx.WaitForChildren()
+}
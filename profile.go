@@ -0,0 +1,213 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+)
+
+// Sampling rate, as float64 bits.
+var sampleRate atomic.Uint64
+
+// SetSampleRate sets the fraction (between 0 and 1) of contexts for which the
+// creation site and lifetime are recorded (see Samples()). This is meant to
+// diagnose context churn in large services with low overhead. A rate of 0
+// (the default) disables sampling.
+func SetSampleRate(rate float64) {
+	if rate < 0 || rate > 1 {
+		panic("sample rate must be between 0 and 1")
+	}
+
+	sampleRate.Store(math.Float64bits(rate))
+}
+
+// SampleReport is a report of the contexts sampled so far (see
+// SetSampleRate()).
+type SampleReport struct {
+	// Derivation sites, sorted by the number of sampled contexts created at
+	// them (hottest first).
+	Sites []SampleSite
+
+	// Sampled contexts that are not done yet, sorted by age (oldest
+	// first).
+	LongestLived []SampledContext
+}
+
+// SampleSite holds statistics about the sampled contexts created at a given
+// site.
+type SampleSite struct {
+	// Function, file and line that created the contexts.
+	Site string
+
+	// Number of sampled contexts created and how many of those are not done
+	// yet.
+	Count int
+	Live  int
+
+	// Total and maximum lifetime of the sampled contexts that are done.
+	TotalLifetime time.Duration
+	MaxLifetime   time.Duration
+}
+
+// SampledContext describes a sampled context that is not done yet.
+type SampledContext struct {
+	Site        string
+	Description string
+	Age         time.Duration
+}
+
+// Samples returns a report of all contexts sampled so far.
+func Samples() SampleReport {
+	profiler.mu.Lock()
+	defer profiler.mu.Unlock()
+
+	var report SampleReport
+
+	for _, site := range profiler.sites {
+		report.Sites = append(report.Sites, *site)
+	}
+
+	sort.Slice(report.Sites, func(i, j int) bool {
+		return report.Sites[i].Count > report.Sites[j].Count
+	})
+
+	for s := range profiler.live {
+		c := s.ctx.Value()
+		if c == nil {
+			continue
+		}
+
+		report.LongestLived = append(report.LongestLived, SampledContext{
+			Site:        s.site.Site,
+			Description: c.String(),
+			Age:         time.Since(s.created),
+		})
+	}
+
+	sort.Slice(report.LongestLived, func(i, j int) bool {
+		return report.LongestLived[i].Age > report.LongestLived[j].Age
+	})
+
+	return report
+}
+
+// ResetSamples discards all samples collected so far. Contexts sampled before
+// are not reported anymore, even if they are still live.
+func ResetSamples() {
+	profiler.mu.Lock()
+	defer profiler.mu.Unlock()
+
+	profiler.sites = nil
+	profiler.live = nil
+}
+
+var profiler struct {
+	mu sync.Mutex
+
+	sites map[string]*SampleSite
+	live  map[*sample]struct{}
+}
+
+// sample is a sampled context.
+type sample struct {
+	ctx     weak.Pointer[ctxImpl]
+	site    *SampleSite
+	created time.Time
+	ended   atomic.Bool
+}
+
+// maybeSample samples c according to the current sampling rate. It must be
+// called by newCtx.
+func maybeSample(c *ctxImpl) {
+	rate := math.Float64frombits(sampleRate.Load())
+	if rate == 0 || rand.Float64() >= rate {
+		return
+	}
+
+	pcs := make([]uintptr, 16)
+
+	// Skip runtime.Callers, maybeSample and newCtx.
+	site := derivationSite(pcs[:runtime.Callers(3, pcs)])
+
+	profiler.mu.Lock()
+
+	if profiler.sites == nil {
+		profiler.sites = make(map[string]*SampleSite)
+		profiler.live = make(map[*sample]struct{})
+	}
+
+	stats := profiler.sites[site]
+	if stats == nil {
+		stats = &SampleSite{Site: site}
+		profiler.sites[site] = stats
+	}
+	stats.Count++
+	stats.Live++
+
+	s := &sample{
+		ctx:     weak.Make(c),
+		site:    stats,
+		created: c.created,
+	}
+	profiler.live[s] = struct{}{}
+
+	profiler.mu.Unlock()
+
+	// The lifetime ends when the context is done or when it becomes
+	// unreachable, whatever happens first.
+	context.AfterFunc(c.Context, s.end)
+	runtime.AddCleanup(c, func(s *sample) { s.end() }, s)
+}
+
+func (s *sample) end() {
+	if s.ended.Swap(true) {
+		return
+	}
+
+	lifetime := time.Since(s.created)
+
+	profiler.mu.Lock()
+	defer profiler.mu.Unlock()
+
+	if _, ok := profiler.live[s]; !ok {
+		// Discarded by ResetSamples().
+		return
+	}
+
+	delete(profiler.live, s)
+
+	s.site.Live--
+	s.site.TotalLifetime += lifetime
+	s.site.MaxLifetime = max(s.site.MaxLifetime, lifetime)
+}
+
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// derivationSite returns a description of the first frame in stack that is
+// not part of this package.
+func derivationSite(stack []uintptr) string {
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, filepath.Base(frame.File), frame.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package context
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamples(t *testing.T) {
+	ResetSamples()
+	SetSampleRate(1)
+	defer SetSampleRate(0)
+
+	parent := WithName(Background(), "long-lived")
+
+	for i := 0; i < 10; i++ {
+		_, cancel := WithCancel(parent)
+		cancel()
+	}
+
+	// Lifetimes end asynchronously.
+	var report SampleReport
+	var hottest *SampleSite
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		report = Samples()
+
+		hottest = nil
+		for i, site := range report.Sites {
+			if strings.Contains(site.Site, "TestSamples") {
+				hottest = &report.Sites[i]
+				break
+			}
+		}
+
+		if hottest != nil && hottest.Live == 0 {
+			break
+		}
+	}
+
+	if hottest == nil {
+		t.Fatalf("Expected test site to be reported. Got %v.", report.Sites)
+	}
+	if hottest.Count != 10 || hottest.Live != 0 {
+		t.Errorf("Expected 10 contexts, none live, for hottest site. Got %d, %d live.",
+			hottest.Count, hottest.Live)
+	}
+	if !strings.Contains(hottest.Site, "profile_test.go") {
+		t.Errorf("Expected site to include the file name. Got %q.", hottest.Site)
+	}
+
+	found := false
+	for _, sampled := range report.LongestLived {
+		if strings.Contains(sampled.Description, "long-lived") {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected long-lived context to be reported. Got %v.", report.LongestLived)
+	}
+}
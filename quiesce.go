@@ -0,0 +1,84 @@
+package context
+
+import (
+	"sync"
+)
+
+// quiesce tracks if a context was quiesced (see Quiesce()).
+type quiesce struct {
+	mu sync.Mutex
+
+	// Created on demand.
+	ch chan struct{}
+
+	done bool
+}
+
+func (n *node) Quiesce() {
+	q := &n.ext().quiesce
+
+	q.mu.Lock()
+	if q.done {
+		q.mu.Unlock()
+		return
+	}
+
+	q.done = true
+	if q.ch != nil {
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	// Descendants that did not call Quiescing() yet check their ancestors
+	// when they do.
+	for _, kid := range n.liveKids() {
+		kid.Quiesce()
+	}
+}
+
+func (c *ctxImpl) Quiescing() <-chan struct{} {
+	// Tracked first, so a concurrent Quiesce() on an ancestor either finds c
+	// or is seen by ancestorQuiesced() below.
+	c.track()
+
+	q := &c.ext().quiesce
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.ch == nil {
+		q.ch = make(chan struct{})
+		if !q.done && c.ancestorQuiesced() {
+			q.done = true
+		}
+		if q.done {
+			close(q.ch)
+		}
+	}
+
+	return q.ch
+}
+
+// ancestorQuiesced returns true if any ancestor of n was quiesced.
+func (n *node) ancestorQuiesced() bool {
+	for parent := n.parent; parent != nil; {
+		p := nodeOf(parent)
+		if p == nil {
+			break
+		}
+
+		if x := p.extras.Load(); x != nil {
+			x.quiesce.mu.Lock()
+			done := x.quiesce.done
+			x.quiesce.mu.Unlock()
+
+			if done {
+				return true
+			}
+		}
+
+		parent = p.parent
+	}
+
+	return false
+}
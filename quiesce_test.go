@@ -0,0 +1,54 @@
+package context
+
+import (
+	"testing"
+)
+
+func TestQuiesce(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	child := WithName(parent, "child")
+
+	select {
+	case <-child.Quiescing():
+		t.Fatalf("Expected child not to be quiescing.")
+	default:
+	}
+
+	processed := 0
+	started := make(chan struct{})
+	go func(ctx Context) {
+		defer ctx.Finished()
+
+		for {
+			select {
+			case <-ctx.Quiescing():
+				return
+			default:
+				processed++
+				if processed == 1 {
+					close(started)
+				}
+			}
+		}
+	}(EnableWait(child))
+
+	<-started
+	parent.Quiesce()
+	parent.WaitForChildren()
+
+	if processed == 0 {
+		t.Errorf("Expected some items to be processed.")
+	}
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+
+	// Contexts derived later are also quiescing.
+	select {
+	case <-WithName(child, "late").Quiescing():
+	default:
+		t.Errorf("Expected late child to be quiescing.")
+	}
+}
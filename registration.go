@@ -0,0 +1,168 @@
+package context
+
+import (
+	"context"
+	"sync"
+)
+
+// Registration is a handle to a single wait registration of a Context (see
+// EnableWaitHandle() and EnableWaitFinishOnCancel()). Its Finished() method
+// can be safely called any number of times, so completion can be deferred in
+// multiple error paths without risking unbalanced Finished() calls.
+type Registration struct {
+	ctx Context
+
+	mu       sync.Mutex
+	started  bool
+	finished bool
+
+	// Set for registrations created with EnableWaitFinishOnCancel().
+	finishOnCancel bool
+
+	// Stops the automatic finishing on cancellation (if any).
+	stop func() bool
+}
+
+// EnableWaitHandle is like EnableWait but returns a Registration handle for
+// the registration. Calling Finished() on the handle is guarded so only the
+// first call has any effect.
+func EnableWaitHandle(ctx Context) *Registration {
+	EnableWait(ctx)
+
+	return &Registration{
+		ctx:  ctx,
+		stop: func() bool { return false },
+	}
+}
+
+// FinishedOnce returns a function that calls ctx.Finished() the first time it
+// is called and does nothing on subsequent calls.
+func FinishedOnce(ctx Context) func() {
+	return sync.OnceFunc(ctx.Finished)
+}
+
+// EnableWaitFinishOnCancel is like EnableWait but the registration counts as
+// finished if ctx is canceled before the work associated with it starts. This
+// is useful for queued work that might never run, so shutdown does not wait
+// on it.
+//
+// The work must call Start() on the returned Registration before it starts
+// and must only proceed if it returns true. Completion is reported by calling
+// Finished() on the Registration (or on the Context itself).
+func EnableWaitFinishOnCancel(ctx Context) *Registration {
+	EnableWait(ctx)
+
+	r := &Registration{
+		ctx:            ctx,
+		finishOnCancel: true,
+	}
+	r.stop = context.AfterFunc(ctx, func() { r.finishIfNotStarted(ctx) })
+
+	return r
+}
+
+// Context returns the registered Context.
+func (r *Registration) Context() Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ctx
+}
+
+// Start marks the work associated with the registration as started. It
+// returns false if the Context was canceled before that, in which case the
+// registration was already counted as finished and the work must not run.
+func (r *Registration) Start() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.finished {
+		return false
+	}
+
+	r.started = true
+	r.stop()
+
+	return true
+}
+
+// Finished reports that the work associated with the registration finished.
+// It is a no-op if the registration was already counted as finished.
+func (r *Registration) Finished() {
+	r.mu.Lock()
+
+	if r.finished {
+		r.mu.Unlock()
+		return
+	}
+
+	r.finished = true
+	r.stop()
+	ctx := r.ctx
+
+	r.mu.Unlock()
+
+	ctx.Finished()
+}
+
+// TransferTo moves the registration to other, so it is waited on by other
+// instead of by the original parent. The registration Context (see
+// Context()) is replaced by a new Context derived from other (with the same
+// name), which should be used by the work from now on. The new registration
+// is created before the original one is finished, so there is no gap in the
+// accounting of either parent.
+//
+// It returns false (and does nothing) if the registration already finished
+// or if it could not be registered with other (for example, because of its
+// limit, see SetMaxChildren()), in which case the misuse is also reported
+// (see SetMisusePolicy()) and the original registration stays in place.
+func (r *Registration) TransferTo(other Context) bool {
+	r.mu.Lock()
+
+	if r.finished {
+		r.mu.Unlock()
+		return false
+	}
+
+	old := r.ctx
+
+	c := newCtx(stdContext(other), other)
+	if n := nodeOf(old); n != nil {
+		c.name = n.name
+	}
+	ctx, err := enableWait(c, 1, "TransferTo", false)
+	if err != nil {
+		r.mu.Unlock()
+		return false
+	}
+	r.ctx = ctx
+
+	r.stop()
+	if r.finishOnCancel && !r.started {
+		ctx := r.ctx
+		r.stop = context.AfterFunc(ctx, func() { r.finishIfNotStarted(ctx) })
+	}
+
+	r.mu.Unlock()
+
+	old.Finished()
+
+	return true
+}
+
+// finishIfNotStarted finishes the registration if the work did not start yet
+// and the registration was not transferred from ctx in the meantime.
+func (r *Registration) finishIfNotStarted(ctx Context) {
+	r.mu.Lock()
+
+	if r.started || r.finished || r.ctx != ctx {
+		r.mu.Unlock()
+		return
+	}
+
+	r.finished = true
+
+	r.mu.Unlock()
+
+	ctx.Finished()
+}
@@ -0,0 +1,172 @@
+package context
+
+import (
+	"testing"
+)
+
+func TestEnableWaitFinishOnCancel(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+
+	r := EnableWaitFinishOnCancel(ctx)
+
+	// Work is canceled before it starts.
+	cancel()
+
+	parent.WaitForChildren()
+
+	if r.Start() {
+		t.Errorf("Expected Start() to fail for canceled registration.")
+	}
+
+	// No-op.
+	r.Finished()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestEnableWaitFinishOnCancel_Started(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	r := EnableWaitFinishOnCancel(ctx)
+
+	if !r.Start() {
+		t.Fatalf("Expected Start() to succeed.")
+	}
+
+	// Started work is not finished by cancellation.
+	cancel()
+
+	if n := WaitCount(ctx); n != 1 {
+		t.Errorf("Expected wait count to be 1. Got %d.", n)
+	}
+
+	go r.Finished()
+
+	parent.WaitForChildren()
+
+	if r.Context() != ctx {
+		t.Errorf("Expected registration context to be %v. Got %v.", ctx, r.Context())
+	}
+}
+
+func TestEnableWaitHandle(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	r := EnableWaitHandle(ctx)
+
+	if !r.Start() {
+		t.Errorf("Expected Start() to succeed.")
+	}
+
+	go func() {
+		defer r.Finished()
+		defer r.Finished()
+
+		r.Finished()
+	}()
+
+	parent.WaitForChildren()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestFinishedOnce(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	finished := FinishedOnce(EnableWait(ctx))
+
+	go func() {
+		defer finished()
+		finished()
+	}()
+
+	parent.WaitForChildren()
+
+	finished()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
+
+func TestRegistration_TransferTo(t *testing.T) {
+	requests := Background()
+	background := Background()
+
+	ctx, cancel := WithCancel(WithName(requests, "task"))
+	defer cancel()
+
+	r := EnableWaitFinishOnCancel(ctx)
+
+	if !r.TransferTo(WithName(background, "pool")) {
+		t.Fatalf("Expected transfer to succeed.")
+	}
+
+	// The original parent does not wait anymore.
+	requests.WaitForChildren()
+
+	// Canceling the original context does not finish the transferred
+	// registration.
+	cancel()
+
+	if !r.Start() {
+		t.Fatalf("Expected Start() to succeed.")
+	}
+	if n := WaitCount(r.Context()); n != 1 {
+		t.Errorf("Expected wait count to be 1. Got %d.", n)
+	}
+
+	go r.Finished()
+
+	r.Context().Parent().WaitForChildren()
+
+	if r.TransferTo(requests) {
+		t.Errorf("Expected transfer of finished registration to fail.")
+	}
+}
+
+func TestRegistration_TransferToRejected(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	requests := Background()
+
+	background := Background()
+	SetMaxChildren(background, 1, false)
+
+	full := EnableWait(WithName(background, "full"))
+	defer full.Finished()
+
+	r := EnableWaitHandle(WithName(requests, "task"))
+	ctx := r.Context()
+
+	if r.TransferTo(background) {
+		t.Fatalf("Expected transfer to fail.")
+	}
+
+	// The original registration is still in place.
+	if r.Context() != ctx {
+		t.Errorf("Expected registration context to be %v. Got %v.", ctx, r.Context())
+	}
+	if n := WaitCount(ctx); n != 1 {
+		t.Errorf("Expected wait count to be 1. Got %d.", n)
+	}
+
+	r.Finished()
+	requests.WaitForChildren()
+}
@@ -0,0 +1,49 @@
+package context
+
+import (
+	"sync"
+)
+
+// Results collects values sent by the children of a Context. It provides a
+// safe way of returning data from waited goroutines without sharing
+// variables: everything sent before a child calls Finished() is visible to
+// the goroutine that waits on the parent.
+type Results[T any] struct {
+	ctx Context
+
+	mu      sync.Mutex
+	results []T
+}
+
+// NewResults returns a Results that collects values sent by children of
+// ctx.
+func NewResults[T any](ctx Context) *Results[T] {
+	return &Results[T]{
+		ctx: ctx,
+	}
+}
+
+// Send records the given result. It is meant to be called by children
+// before they call Finished().
+func (r *Results[T]) Send(result T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, result)
+}
+
+// Wait waits for all children of the associated Context to finish (see
+// WaitForChildren()) and then returns all results sent so far, in the order
+// they were sent. Results returned are removed, so the Results can be reused
+// for the next wave of children.
+func (r *Results[T]) Wait() []T {
+	r.ctx.WaitForChildren()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := r.results
+	r.results = nil
+
+	return results
+}
@@ -0,0 +1,33 @@
+package context
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestResults(t *testing.T) {
+	parent := Background()
+
+	results := NewResults[int](parent)
+
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 10; i++ {
+			go func(ctx Context) {
+				defer ctx.Finished()
+				results.Send(round*10 + i)
+			}(EnableWait(WithName(parent, "child")))
+		}
+
+		got := results.Wait()
+		if len(got) != 10 {
+			t.Fatalf("Expected 10 results. Got %d.", len(got))
+		}
+
+		sort.Ints(got)
+		for i, value := range got {
+			if value != round*10+i {
+				t.Errorf("Expected result %d to be %d. Got %d.", i, round*10+i, value)
+			}
+		}
+	}
+}
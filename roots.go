@@ -0,0 +1,85 @@
+package context
+
+import (
+	"sort"
+	"sync"
+)
+
+var roots struct {
+	mu sync.Mutex
+
+	byName map[string]Context
+}
+
+// RegisterRoot registers ctx under the given name in the process-wide
+// registry of long-lived contexts (see Roots()), so operational tooling can
+// discover all context trees in the process. Registering a name again
+// replaces the previous context. It returns a function that removes the
+// registration.
+func RegisterRoot(name string, ctx Context) (unregister func()) {
+	roots.mu.Lock()
+	defer roots.mu.Unlock()
+
+	if roots.byName == nil {
+		roots.byName = make(map[string]Context)
+	}
+	roots.byName[name] = ctx
+
+	return func() {
+		roots.mu.Lock()
+		defer roots.mu.Unlock()
+
+		if roots.byName[name] == ctx {
+			delete(roots.byName, name)
+		}
+	}
+}
+
+// RootInfo describes a context registered with RegisterRoot().
+type RootInfo struct {
+	Name    string
+	Context Context
+
+	// Number of live tracked contexts in the tree (including the root
+	// itself, see Children()) and total number of pending children across
+	// all of them.
+	Contexts        int
+	PendingChildren int
+}
+
+// Roots returns information about all contexts registered with
+// RegisterRoot(), sorted by name.
+func Roots() []RootInfo {
+	roots.mu.Lock()
+	infos := make([]RootInfo, 0, len(roots.byName))
+	for name, ctx := range roots.byName {
+		infos = append(infos, RootInfo{Name: name, Context: ctx})
+	}
+	roots.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	for i := range infos {
+		if c := lookup(infos[i].Context); c != nil {
+			infos[i].Contexts, infos[i].PendingChildren = subtreeStats(c)
+		}
+	}
+
+	return infos
+}
+
+// subtreeStats returns the number of live contexts in the tree rooted at c
+// and the total number of pending children in it.
+func subtreeStats(c *ctxImpl) (contexts, pending int) {
+	contexts, pending = 1, c.childrenWg.count()
+
+	for _, kid := range c.liveKids() {
+		kidContexts, kidPending := subtreeStats(kid)
+		contexts += kidContexts
+		pending += kidPending
+	}
+
+	return contexts, pending
+}
@@ -0,0 +1,61 @@
+package context
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterRoot(t *testing.T) {
+	root := Background()
+
+	unregister := RegisterRoot("test-root", root)
+
+	ctx, cancel := WithCancel(root)
+	defer cancel()
+
+	worker := EnableWait(WithName(ctx, "worker"))
+	defer worker.Finished()
+
+	var info *RootInfo
+	for _, i := range Roots() {
+		if i.Name == "test-root" {
+			info = &i
+		}
+	}
+
+	if info == nil {
+		t.Fatalf("Expected root to be registered.")
+	}
+	if info.Contexts != 3 || info.PendingChildren != 1 {
+		t.Errorf("Expected 3 contexts and 1 pending child. Got %d and %d.",
+			info.Contexts, info.PendingChildren)
+	}
+
+	rec := httptest.NewRecorder()
+	DebugHandler(nil).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/contexts?format=json", nil))
+
+	var tree debugNode
+	if err := json.Unmarshal(rec.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	found := false
+	for _, kid := range tree.Children {
+		if kid.Name == "test-root" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected registered root in debug tree. Got %+v.", tree)
+	}
+
+	unregister()
+
+	for _, i := range Roots() {
+		if i.Name == "test-root" {
+			t.Errorf("Expected root to be unregistered.")
+		}
+	}
+}
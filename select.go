@@ -0,0 +1,34 @@
+package context
+
+import (
+	"reflect"
+)
+
+// FirstDone blocks until any of the given contexts is done. It returns the
+// index of that context and its error. If more than one context is done, one
+// of them is chosen randomly. If no contexts are given, it returns -1 and nil
+// immediately.
+func FirstDone(ctxs ...Context) (int, error) {
+	if len(ctxs) == 0 {
+		return -1, nil
+	}
+
+	// Fast path. Check if any of them is already done.
+	for i, ctx := range ctxs {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+	}
+
+	cases := make([]reflect.SelectCase, len(ctxs))
+	for i, ctx := range ctxs {
+		cases[i] = reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(ctx.Done()),
+		}
+	}
+
+	i, _, _ := reflect.Select(cases)
+
+	return i, ctxs[i].Err()
+}
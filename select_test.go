@@ -0,0 +1,31 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstDone(t *testing.T) {
+	request, cancelRequest := WithCancel(Background())
+	defer cancelRequest()
+
+	reload, cancelReload := WithTimeout(Background(), 1*time.Millisecond)
+	defer cancelReload()
+
+	i, err := FirstDone(request, reload)
+	if i != 1 {
+		t.Errorf("Expected index to be 1. Got %d.", i)
+	}
+	if err != DeadlineExceeded {
+		t.Errorf("Expected error to be %v. Got %v.", DeadlineExceeded, err)
+	}
+
+	cancelRequest()
+
+	if i, err := FirstDone(request); i != 0 || err != Canceled {
+		t.Errorf("Expected 0 and %v. Got %d and %v.", Canceled, i, err)
+	}
+	if i, err := FirstDone(); i != -1 || err != nil {
+		t.Errorf("Expected -1 and nil. Got %d and %v.", i, err)
+	}
+}
@@ -0,0 +1,159 @@
+package context
+
+import (
+	"sync"
+)
+
+// flightGroup tracks the in-flight calls made with Do() under a root.
+type flightGroup struct {
+	mu sync.Mutex
+
+	calls map[any]*flight
+}
+
+// flight is an in-flight (or completed) call.
+type flight struct {
+	// Set once the flight is registered. If all callers lose interest
+	// before that, abandoned is set instead.
+	cancel    CancelFunc
+	abandoned bool
+
+	// Number of callers still interested in the result.
+	waiters int
+
+	// Closed once val and err are set.
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// Do executes fn and returns its results, making sure that only one
+// execution is in flight for a given key among all callers under the same
+// root context (see Root()). Duplicate callers wait for the original call to
+// complete and receive the same results.
+//
+// fn runs in its own goroutine (see Go()) with a context derived from the root
+// (so it does not carry the values of any specific caller) and calling
+// WaitForChildren() on the root waits for it to return. If all
+// callers waiting on it are done, that context is canceled, and each of them
+// returns its own ctx.Err(). Panics in fn are handled according to the
+// context of the caller that started the call (see WithCancelOnPanic()).
+// Callers using the same key must use the same type T.
+func Do[T any](ctx Context, key any, fn func(Context) (T, error)) (T, error) {
+	root := lookup(ctx.Root())
+	if root == nil {
+		return fn(ctx)
+	}
+
+	g := &root.ext().flights
+
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[any]*flight)
+	}
+
+	f, ok := g.calls[key]
+	if ok {
+		f.waiters++
+		g.mu.Unlock()
+	} else {
+		f = &flight{
+			waiters: 1,
+			done:    make(chan struct{}),
+		}
+		g.calls[key] = f
+		g.mu.Unlock()
+
+		// The flight is started without holding the lock, as registering it
+		// might block (see SetMaxChildren()) and runs derive hooks.
+		g.start(root, ctx, key, f, func(ctx Context) (any, error) {
+			return fn(ctx)
+		})
+	}
+
+	select {
+	case <-f.done:
+		val, _ := f.val.(T)
+		return val, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		var cancel CancelFunc
+		if f.waiters == 0 {
+			// Nobody is interested anymore. Future callers start a new
+			// call.
+			g.remove(key, f)
+			cancel = f.cancel
+			f.abandoned = cancel == nil
+		}
+		g.mu.Unlock()
+
+		if cancel != nil {
+			cancel()
+		}
+
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// start runs fn for f in a new goroutine reporting to root, on behalf of the
+// caller with the given ctx.
+func (g *flightGroup) start(root *ctxImpl, ctx Context, key any, f *flight, fn func(Context) (any, error)) {
+	fctx, cancel := WithCancel(WithName(root, "singleflight"))
+
+	// Panics are handled as if fn was launched by the caller (see
+	// WithCancelOnPanic()).
+	if caller := nodeOf(ctx); caller != nil {
+		nodeOf(fctx).setInherited(func(i *inherited) {
+			i.panicCancel = caller.config().panicCancel
+		})
+	}
+
+	finish := func(val any, err error) {
+		g.mu.Lock()
+		f.val, f.err = val, err
+		g.remove(key, f)
+		g.mu.Unlock()
+
+		close(f.done)
+	}
+
+	if err := goOn(root, fctx, func(child Context) error {
+		// Recovered panics (see WithCancelOnPanic()) are also published, so
+		// callers do not wait forever.
+		var val any
+		err := runChild(child, func(child Context) error {
+			var err error
+			val, err = fn(child)
+			return err
+		})
+		finish(val, err)
+
+		return err
+	}, cancel, false); err != nil {
+		// Could not be registered.
+		finish(nil, err)
+		return
+	}
+
+	// Only cancelable once registered, so losing interest while waiting for
+	// registration (see SetMaxChildren()) is not a registration failure.
+	g.mu.Lock()
+	f.cancel = cancel
+	abandoned := f.abandoned
+	g.mu.Unlock()
+
+	if abandoned {
+		cancel()
+	}
+}
+
+// remove removes f from the in-flight calls, if it is still the call for
+// key. It must be called with the lock held.
+func (g *flightGroup) remove(key any, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
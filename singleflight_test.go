@@ -0,0 +1,122 @@
+package context
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	root := Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := WithCancel(root)
+			defer cancel()
+
+			v, err := Do(ctx, "key", func(ctx Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			if err != nil {
+				t.Errorf("Unexpected error %v.", err)
+			}
+			results[i] = v
+		}()
+	}
+
+	time.Sleep(1 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	root.WaitForChildren()
+
+	if n := calls.Load(); n < 1 || n > 5 {
+		t.Errorf("Expected calls to be shared. Got %d calls.", n)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("Expected result %d to be 42. Got %d.", i, v)
+		}
+	}
+}
+
+func TestDo_AllCallersCanceled(t *testing.T) {
+	root := Background()
+
+	ctx, cancel := WithCancel(root)
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, err := Do(ctx, "key", func(ctx Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return 0, ctx.Err()
+	})
+
+	if err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Expected shared call to be canceled.")
+	}
+
+	root.WaitForChildren()
+}
+
+func TestDo_Limited(t *testing.T) {
+	root := Background()
+	SetMaxChildren(root, 1, true)
+
+	release := make(chan struct{})
+	first := make(chan int)
+	go func() {
+		v, _ := Do(root, "first", func(ctx Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		first <- v
+	}()
+
+	// Blocks for a slot until the first call finishes, without holding up
+	// the first call.
+	second := make(chan int)
+	go func() {
+		v, _ := Do(root, "second", func(ctx Context) (int, error) {
+			return 2, nil
+		})
+		second <- v
+	}()
+
+	close(release)
+
+	for _, ch := range []chan int{first, second} {
+		select {
+		case <-ch:
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Expected calls to complete.")
+		}
+	}
+
+	root.WaitForChildren()
+}
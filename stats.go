@@ -0,0 +1,196 @@
+package context
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WaitStats holds statistics about the wait related activity of a Context.
+type WaitStats struct {
+	// Number of children Finished() calls.
+	ChildrenFinished int
+
+	// Total and maximum time between EnableWait() and Finished() calls of
+	// children. Only timed registrations count (see SetWaitTimings()).
+	ChildrenTime    time.Duration
+	ChildrenMaxTime time.Duration
+
+	// The child that took the longest to finish (ChildrenMaxTime).
+	SlowestChild Context
+
+	// Number of WaitForChildren() calls (including variations).
+	Waits int
+
+	// Total and maximum time WaitForChildren() calls blocked.
+	WaitTime    time.Duration
+	WaitMaxTime time.Duration
+}
+
+// MetricsHook receives wait related measurements for all contexts. See
+// SetMetricsHook().
+type MetricsHook interface {
+	// ChildFinished is called when a child calls Finished() with the time
+	// since the corresponding EnableWait() call.
+	ChildFinished(child Context, d time.Duration)
+
+	// WaitedForChildren is called when a WaitForChildren() call (or any of
+	// its variations) returns with the time it blocked.
+	WaitedForChildren(ctx Context, d time.Duration)
+}
+
+var metricsHook atomic.Pointer[MetricsHook]
+
+// SetMetricsHook sets the hook that receives wait related measurements for
+// all contexts. Passing nil removes the current hook. While a hook is set,
+// registrations are timed (see SetWaitTimings()).
+func SetMetricsHook(hook MetricsHook) {
+	if hook == nil {
+		metricsHook.Store(nil)
+		return
+	}
+
+	metricsHook.Store(&hook)
+}
+
+// SetWaitTimings enables or disables recording the time between EnableWait()
+// and Finished() calls of children (see WaitStats() and MetricsHook) and the
+// times contexts are canceled and children last finished (see Lifecycle). It
+// is disabled by default, as it has a cost on every registration and
+// cancellation, and it is always enabled while a metrics hook is set (see
+// SetMetricsHook()). Registrations made while it is disabled are not timed.
+func SetWaitTimings(enabled bool) {
+	waitTimings.Store(enabled)
+}
+
+var waitTimings atomic.Bool
+
+// timingsEnabled returns true if registrations must be timed.
+func timingsEnabled() bool {
+	return waitTimings.Load() || metricsHook.Load() != nil
+}
+
+// stats holds the wait statistics of a context, except for the number of
+// finished children (see node.childrenFinished).
+type stats struct {
+	mu sync.Mutex
+
+	stats WaitStats
+
+	// Start time of each pending timed EnableWait() call, in order, starting
+	// at index head.
+	enabledAt []time.Time
+	head      int
+}
+
+func (n *node) WaitStats() WaitStats {
+	var stats WaitStats
+	if x := n.extras.Load(); x != nil {
+		x.stats.mu.Lock()
+		stats = x.stats.stats
+		x.stats.mu.Unlock()
+	}
+
+	stats.ChildrenFinished = int(n.childrenFinished.Load())
+
+	return stats
+}
+
+func (n *node) pushEnabled(count int) {
+	if !timingsEnabled() {
+		return
+	}
+
+	now := time.Now()
+
+	s := &n.ext().stats
+
+	s.mu.Lock()
+	for i := 0; i < count; i++ {
+		s.enabledAt = append(s.enabledAt, now)
+	}
+	s.mu.Unlock()
+}
+
+// popEnabled returns the time since the oldest pending timed EnableWait()
+// call or zero if there is none.
+func (n *node) popEnabled() time.Duration {
+	x := n.extras.Load()
+	if x == nil {
+		return 0
+	}
+
+	s := &x.stats
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.head == len(s.enabledAt) {
+		return 0
+	}
+
+	start := s.enabledAt[s.head]
+
+	s.head++
+	if s.head == len(s.enabledAt) {
+		// Empty. Reuse the storage.
+		s.enabledAt = s.enabledAt[:0]
+		s.head = 0
+	}
+
+	return time.Since(start)
+}
+
+// recordChildFinished records that child finished d after it was registered
+// (zero if the registration was not timed).
+func (n *node) recordChildFinished(child Context, d time.Duration) {
+	n.childrenFinished.Add(1)
+
+	if d > 0 {
+		s := &n.ext().stats
+
+		s.mu.Lock()
+
+		stats := &s.stats
+		stats.ChildrenTime += d
+		if d >= stats.ChildrenMaxTime {
+			stats.ChildrenMaxTime = d
+			stats.SlowestChild = child
+		}
+
+		s.mu.Unlock()
+	}
+
+	if hook := metricsHook.Load(); hook != nil {
+		(*hook).ChildFinished(child, d)
+	}
+}
+
+// beginWait must be called when a WaitForChildren() call (or any of its
+// variations) starts. It returns the start time to be passed to recordWait()
+// once it returns.
+func beginWait() time.Time {
+	waitsInProgress.Add(1)
+	return time.Now()
+}
+
+func (n *node) recordWait(ctx Context, d time.Duration) {
+	waitsInProgress.Add(-1)
+
+	st := &n.ext().stats
+
+	st.mu.Lock()
+
+	s := &st.stats
+	s.Waits++
+	s.WaitTime += d
+	if d > s.WaitMaxTime {
+		s.WaitMaxTime = d
+	}
+
+	st.mu.Unlock()
+
+	if hook := metricsHook.Load(); hook != nil {
+		(*hook).WaitedForChildren(ctx, d)
+	}
+}
@@ -0,0 +1,72 @@
+package context
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testHook struct {
+	mu       sync.Mutex
+	finished []time.Duration
+	waited   []time.Duration
+}
+
+func (h *testHook) ChildFinished(child Context, d time.Duration) {
+	h.mu.Lock()
+	h.finished = append(h.finished, d)
+	h.mu.Unlock()
+}
+
+func (h *testHook) WaitedForChildren(ctx Context, d time.Duration) {
+	h.mu.Lock()
+	h.waited = append(h.waited, d)
+	h.mu.Unlock()
+}
+
+func TestWaitStats(t *testing.T) {
+	hook := &testHook{}
+	SetMetricsHook(hook)
+	defer SetMetricsHook(nil)
+
+	parent := Background()
+
+	fast, cancel := WithCancel(parent)
+	defer cancel()
+
+	slow, cancel := WithCancel(parent)
+	defer cancel()
+
+	go func(ctx Context) {
+		ctx.Finished()
+	}(EnableWait(fast))
+
+	go func(ctx Context) {
+		time.Sleep(5 * time.Millisecond)
+		ctx.Finished()
+	}(EnableWait(slow))
+
+	parent.WaitForChildren()
+
+	stats := parent.WaitStats()
+	if stats.ChildrenFinished != 2 {
+		t.Errorf("Expected 2 finished children. Got %d.", stats.ChildrenFinished)
+	}
+	if stats.SlowestChild != slow {
+		t.Errorf("Expected slowest child to be %v. Got %v.", slow, stats.SlowestChild)
+	}
+	if stats.ChildrenMaxTime < 5*time.Millisecond {
+		t.Errorf("Expected max child time to be at least 5ms. Got %v.", stats.ChildrenMaxTime)
+	}
+	if stats.Waits != 1 || stats.WaitTime <= 0 {
+		t.Errorf("Expected 1 wait with positive time. Got %d and %v.", stats.Waits, stats.WaitTime)
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if len(hook.finished) != 2 || len(hook.waited) != 1 {
+		t.Errorf("Expected 2 finished and 1 waited hook calls. Got %d and %d.",
+			len(hook.finished), len(hook.waited))
+	}
+}
@@ -0,0 +1,81 @@
+package context
+
+import (
+	"sync"
+)
+
+// Store is a concurrency safe mutable key/value store scoped to a Context.
+// Contrary to values attached with the standard library WithValue(), values
+// can be changed at any time and changes are immediately visible to children
+// that are already running. This can be used, for example, by a parent to
+// broadcast configuration updates to its children.
+//
+// Each Context has its own Store. Lookups that do not find a key in it fall
+// back to the Store of the parent Context, so values set on a Store are
+// visible to all derived Contexts while values set by a child do not affect
+// its parent.
+type Store struct {
+	parent *Store
+
+	mu     sync.RWMutex
+	values map[any]any
+}
+
+func (n *node) Store() *Store {
+	x := n.ext()
+	if s := x.store.Load(); s != nil {
+		return s
+	}
+
+	s := &Store{}
+	if n.parent != nil {
+		if p := nodeOf(n.parent); p != nil {
+			s.parent = p.Store()
+		}
+	}
+
+	if !x.store.CompareAndSwap(nil, s) {
+		// Somebody else got there first.
+		return x.store.Load()
+	}
+
+	return s
+}
+
+// Set sets the value associated with key.
+func (s *Store) Set(key, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		s.values = make(map[any]any)
+	}
+
+	s.values[key] = value
+}
+
+// Get returns the value associated with key in this Store or in the Store of
+// the nearest ancestor Context that has it. The boolean result reports
+// whether the key was found.
+func (s *Store) Get(key any) (any, bool) {
+	for ; s != nil; s = s.parent {
+		s.mu.RLock()
+		value, ok := s.values[key]
+		s.mu.RUnlock()
+
+		if ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Delete removes the value associated with key from this Store. Values set
+// on ancestor Stores are not affected (and will be visible again, if any).
+func (s *Store) Delete(key any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+}
@@ -0,0 +1,66 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	parent.Store().Set("key", "parent")
+
+	if value, ok := ctx.Store().Get("key"); !ok || value != "parent" {
+		t.Errorf("Expected value to be %q. Got %v.", "parent", value)
+	}
+
+	ctx.Store().Set("key", "child")
+
+	if value, _ := ctx.Store().Get("key"); value != "child" {
+		t.Errorf("Expected value to be %q. Got %v.", "child", value)
+	}
+	if value, _ := parent.Store().Get("key"); value != "parent" {
+		t.Errorf("Expected parent value to be %q. Got %v.", "parent", value)
+	}
+
+	ctx.Store().Delete("key")
+
+	if value, _ := ctx.Store().Get("key"); value != "parent" {
+		t.Errorf("Expected value to be %q. Got %v.", "parent", value)
+	}
+	if _, ok := ctx.Store().Get("missing"); ok {
+		t.Errorf("Expected missing key to not be found.")
+	}
+}
+
+func TestStore_Broadcast(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	ctx.Store().Set("config", 1)
+
+	updated := make(chan struct{})
+
+	go func(ctx Context) {
+		defer ctx.Finished()
+
+		child := WithName(ctx, "worker")
+
+		<-updated
+
+		if value, _ := child.Store().Get("config"); value != 2 {
+			t.Errorf("Expected updated value to be 2. Got %v.", value)
+		}
+	}(EnableWait(ctx))
+
+	time.Sleep(1 * time.Millisecond)
+	ctx.Store().Set("config", 2)
+	close(updated)
+
+	parent.WaitForChildren()
+}
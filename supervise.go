@@ -0,0 +1,84 @@
+package context
+
+import (
+	"fmt"
+	"time"
+)
+
+// SupervisorSpec describes a child managed by Supervise().
+type SupervisorSpec struct {
+	// Name of the supervised child. Used to name the contexts of each run.
+	Name string
+
+	// Run does the work of the child. It is restarted if it returns an
+	// error or panics.
+	Run func(ctx Context) error
+
+	// Maximum number of restarts. Negative means no limit.
+	MaxRestarts int
+
+	// Delay before the first restart. It doubles after every restart up to
+	// MaxBackoff (if not zero).
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Supervise runs spec.Run in a new goroutine, restarting it (after the
+// configured backoff) every time it returns an error or panics (in which case
+// the error is a *PanicError), up to spec.MaxRestarts times. It stops when a
+// run succeeds, when ctx is done or when there are no more restarts left.
+//
+// The supervisor is a wait-enabled child of ctx and each run is a
+// wait-enabled child of the supervisor, so WaitForChildren() on ctx waits for
+// the whole supervision, errors of all runs are available through
+// ChildrenErr() of the supervisor and the number of runs through its
+// WaitStats(). The supervisor reports the error of the last run when it
+// gives up.
+func Supervise(ctx Context, spec SupervisorSpec) {
+	name := spec.Name
+	if name == "" {
+		name = "supervisor"
+	}
+
+	supervisor, cancel := withCancelName(ctx, name)
+	supervisor = EnableWait(supervisor)
+
+	go func() {
+		untrack := trackGoroutine(supervisor)
+		defer cancel()
+
+		backoff := spec.Backoff
+
+		for restarts := 0; ; restarts++ {
+			run := EnableWait(WithName(supervisor, fmt.Sprintf("%s-%d", name, restarts)))
+
+			err := runRecovered(run, spec.Run)
+			run.FinishedErr(err)
+
+			if err == nil || supervisor.Err() != nil ||
+				(spec.MaxRestarts >= 0 && restarts >= spec.MaxRestarts) {
+				untrack()
+				supervisor.FinishedErr(err)
+				return
+			}
+
+			if backoff > 0 {
+				timer := time.NewTimer(backoff)
+
+				select {
+				case <-timer.C:
+				case <-supervisor.Done():
+					timer.Stop()
+					untrack()
+					supervisor.FinishedErr(err)
+					return
+				}
+
+				backoff *= 2
+				if spec.MaxBackoff > 0 && backoff > spec.MaxBackoff {
+					backoff = spec.MaxBackoff
+				}
+			}
+		}
+	}()
+}
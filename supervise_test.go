@@ -0,0 +1,84 @@
+package context
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSupervise(t *testing.T) {
+	parent := Background()
+
+	runs := 0
+	Supervise(parent, SupervisorSpec{
+		Name: "worker",
+		Run: func(ctx Context) error {
+			runs++
+			switch runs {
+			case 1:
+				panic("boom")
+			case 2:
+				return errors.New("failed")
+			default:
+				return nil
+			}
+		},
+		MaxRestarts: 5,
+		Backoff:     1 * time.Millisecond,
+	})
+
+	parent.WaitForChildren()
+
+	if runs != 3 {
+		t.Errorf("Expected 3 runs. Got %d.", runs)
+	}
+	if err := parent.ChildrenErr(); err != nil {
+		t.Errorf("Expected supervisor to succeed. Got %v.", err)
+	}
+}
+
+func TestSupervise_GiveUp(t *testing.T) {
+	parent := Background()
+
+	errFailed := errors.New("failed")
+
+	runs := 0
+	Supervise(parent, SupervisorSpec{
+		Run: func(ctx Context) error {
+			runs++
+			return errFailed
+		},
+		MaxRestarts: 2,
+	})
+
+	parent.WaitForChildren()
+
+	if runs != 3 {
+		t.Errorf("Expected 3 runs. Got %d.", runs)
+	}
+	if err := parent.ChildrenErr(); !errors.Is(err, errFailed) {
+		t.Errorf("Expected error to be %v. Got %v.", errFailed, err)
+	}
+}
+
+func TestSupervise_CancelChildren(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+
+	Supervise(parent, SupervisorSpec{
+		Run: func(ctx Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		MaxRestarts: -1,
+	})
+
+	parent.CancelChildren(errors.New("wave aborted"))
+
+	// The supervisor gives up instead of restarting.
+	parent.WaitForChildren()
+
+	if parent.Err() != nil {
+		t.Errorf("Expected parent not to be canceled. Got %v.", parent.Err())
+	}
+}
@@ -0,0 +1,114 @@
+package context
+
+import (
+	"sync"
+	"time"
+)
+
+// taggedWaits keeps a wait group per tag for the children registered with
+// EnableWaitTagged().
+type taggedWaits struct {
+	mu sync.Mutex
+
+	groups map[string]*waitGroup
+}
+
+func (t *taggedWaits) group(tag string) *waitGroup {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.groups == nil {
+		t.groups = make(map[string]*waitGroup)
+	}
+
+	wg := t.groups[tag]
+	if wg == nil {
+		wg = &waitGroup{}
+		t.groups[tag] = wg
+	}
+
+	return wg
+}
+
+// EnableWaitTagged is like EnableWait but the registration is also tagged
+// with the given tags (for example, "critical" or "best-effort"), so it can
+// be selectively waited on with WaitForChildrenTagged().
+//
+// It returns a copy of ctx that must be used to report completion (by calling
+// Finished() on it).
+func EnableWaitTagged(ctx Context, tags ...string) Context {
+	n := nodeOf(ctx)
+	if n == nil || n.pNode() == nil {
+		misuse(ctx, "EnableWaitTagged() called on root context %v", ctx)
+		return ctx
+	}
+
+	target := n.waitOn
+	if target == nil {
+		target = n.parent
+	}
+
+	c := newCtx(stdContext(ctx), ctx)
+	c.waitOn = target
+	c.name = n.name
+	c.ext().tags = tags
+
+	// Tagged groups are updated first so waiting on them never misses a
+	// registration that is already visible in the main group.
+	c.addTagged(n.pNode())
+
+	child, err := enableWait(c, 1, "EnableWaitTagged", false)
+	if err != nil {
+		// Not registered.
+		c.finishTagged(n.pNode())
+	}
+
+	return child
+}
+
+// WaitForChildrenTagged is like ctx.WaitForChildren() but only waits for
+// children registered with EnableWaitTagged() with any of the given tags,
+// abandoning all others. If no tags are given or ctx was not created by this
+// package, it just calls ctx.WaitForChildren().
+func WaitForChildrenTagged(ctx Context, tags ...string) {
+	n := nodeOf(ctx)
+	if n == nil || len(tags) == 0 {
+		ctx.WaitForChildren()
+		return
+	}
+
+	if !checkWait(ctx, "WaitForChildrenTagged") {
+		return
+	}
+
+	start := beginWait()
+
+	for _, tag := range tags {
+		n.ext().tagged.group(tag).Wait()
+	}
+
+	n.recordWait(ctx, time.Since(start))
+}
+
+// addTagged registers a tagged child with the given parent.
+func (n *node) addTagged(p *node) {
+	for _, tag := range n.tagsOf() {
+		p.ext().tagged.group(tag).Add(1)
+	}
+}
+
+// finishTagged reports the completion of a tagged child to the given parent.
+func (n *node) finishTagged(p *node) {
+	for _, tag := range n.tagsOf() {
+		p.ext().tagged.group(tag).Done()
+	}
+}
+
+// tagsOf returns the tags of n, if any.
+func (n *node) tagsOf() []string {
+	if x := n.extras.Load(); x != nil {
+		return x.tags
+	}
+
+	return nil
+}
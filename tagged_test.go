@@ -0,0 +1,35 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitForChildrenTagged(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	bestEffort := EnableWaitTagged(ctx, "best-effort")
+	defer bestEffort.Finished()
+
+	done := false
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		done = true
+		ctx.Finished()
+	}(EnableWaitTagged(ctx, "critical"))
+
+	WaitForChildrenTagged(parent, "critical")
+
+	if !done {
+		t.Errorf("Expected critical child to be finished.")
+	}
+	if n := parent.WaitStats().ChildrenFinished; n != 1 {
+		t.Errorf("Expected 1 finished child. Got %d.", n)
+	}
+
+	// Nothing registered with this tag.
+	WaitForChildrenTagged(parent, "other")
+}
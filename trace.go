@@ -0,0 +1,38 @@
+package context
+
+import (
+	"context"
+	"runtime/trace"
+	"sync/atomic"
+)
+
+var traceTasks atomic.Bool
+
+// SetTraceTasks enables or disables the creation of runtime/trace tasks for
+// goroutines launched through Go(). When enabled (and tracing is active),
+// each goroutine gets a task spanning from its registration to its Finished()
+// call and a region covering its execution, so "go tool trace" shows the
+// concurrency structure of the context tree.
+func SetTraceTasks(enabled bool) {
+	traceTasks.Store(enabled)
+}
+
+// startTraceTask creates a new trace task derived from parent if trace tasks
+// are enabled. It returns the context to use and a function that ends the
+// task.
+func startTraceTask(parent Context) (context.Context, func()) {
+	std := stdContext(parent)
+
+	if !traceTasks.Load() || !trace.IsEnabled() {
+		return std, func() {}
+	}
+
+	name := "context.Go"
+	if n := nodeOf(parent); n != nil && n.name != "" {
+		name = n.name
+	}
+
+	std, task := trace.NewTask(std, name)
+
+	return std, task.End
+}
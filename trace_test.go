@@ -0,0 +1,49 @@
+package context
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+)
+
+// traceGo runs a Go() call under a context with the given name while tracing
+// and returns the trace.
+func traceGo(t *testing.T, name string) []byte {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("Tracing not available: %v", err)
+	}
+
+	parent := WithName(Background(), name)
+
+	value := 0
+	Go(parent, func(ctx Context) error {
+		value = 1
+		return nil
+	})
+
+	parent.WaitForChildren()
+
+	trace.Stop()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+
+	return buf.Bytes()
+}
+
+func TestSetTraceTasks(t *testing.T) {
+	// Task names are stored as plain strings in the trace, so their presence
+	// shows that the task was created.
+	if out := traceGo(t, "untraced-task"); bytes.Contains(out, []byte("untraced-task")) {
+		t.Errorf("Expected no task with trace tasks disabled.")
+	}
+
+	SetTraceTasks(true)
+	defer SetTraceTasks(false)
+
+	if out := traceGo(t, "traced-task"); !bytes.Contains(out, []byte("traced-task")) {
+		t.Errorf("Expected a task named %q in the trace.", "traced-task")
+	}
+}
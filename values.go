@@ -0,0 +1,233 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// valuesCtx is the standard library side of a context with multiple values
+// attached by WithValues().
+type valuesCtx struct {
+	context.Context
+
+	// Alternating keys and values.
+	kv []any
+}
+
+func (v *valuesCtx) Value(key any) any {
+	if k, ok := key.(valueAllKey); ok {
+		merged, ok := v.Context.Value(key).([]any)
+		if !ok {
+			// Nothing merged below, so the single value lookup applies.
+			return nil
+		}
+
+		if value, ok := v.lookup(k.key); ok {
+			// Shadows the values of the primary parent of the merge.
+			return append([]any{value}, merged...)
+		}
+
+		return merged
+	}
+
+	if value, ok := v.lookup(key); ok {
+		return value
+	}
+
+	return v.Context.Value(key)
+}
+
+// lookup returns the value associated with key in this layer, if any.
+func (v *valuesCtx) lookup(key any) (any, bool) {
+	// Later pairs take precedence, like they would in a chain of
+	// WithValue() calls.
+	for i := len(v.kv) - 2; i >= 0; i -= 2 {
+		if v.kv[i] == key {
+			return v.kv[i+1], true
+		}
+	}
+
+	return nil, false
+}
+
+func (v *valuesCtx) String() string {
+	keys := make([]string, 0, len(v.kv)/2)
+	for i := 0; i < len(v.kv); i += 2 {
+		keys = append(keys, fmt.Sprint(v.kv[i]))
+	}
+
+	return contextName(v.Context) + ".WithValues(" + strings.Join(keys, ", ") + ")"
+}
+
+// WithValues returns a copy of parent with all the given key/value pairs
+// (key1, value1, key2, value2, ...) attached to it. It is equivalent to a
+// chain of standard library WithValue() calls (including the restrictions
+// on keys) but adds a single layer to the context chain. If the same key is
+// given more than once, the last value wins.
+func WithValues(parent Context, kv ...any) Context {
+	if len(kv)%2 != 0 {
+		panic("odd number of arguments to WithValues")
+	}
+
+	for i := 0; i < len(kv); i += 2 {
+		if kv[i] == nil {
+			panic("nil key")
+		}
+		if !reflect.TypeOf(kv[i]).Comparable() {
+			panic("key is not comparable")
+		}
+	}
+
+	values := make([]any, len(kv))
+	copy(values, kv)
+
+	c := newCtx(&valuesCtx{stdContext(parent), values}, parent)
+
+	derived(parent, c, KindValue)
+
+	return c
+}
+
+var (
+	debugKeysMu sync.RWMutex
+	debugKeys   = map[string]any{}
+)
+
+// RegisterDebugKey makes the value associated with key (if any) be reported
+// by DebugValues() under the given name. Only registered keys are reported so
+// packages can decide which of their values are safe to log. Registering the
+// same name again replaces the previous key.
+func RegisterDebugKey(name string, key any) {
+	debugKeysMu.Lock()
+	defer debugKeysMu.Unlock()
+
+	debugKeys[name] = key
+}
+
+// DebugValues returns the values attached to ctx for all keys registered
+// with RegisterDebugKey(), indexed by their registered names. Keys without
+// a value in ctx are omitted. It is meant to be used for logging which
+// request-scoped values are present at a given point.
+func DebugValues(ctx context.Context) map[string]any {
+	debugKeysMu.RLock()
+	defer debugKeysMu.RUnlock()
+
+	values := make(map[string]any)
+	for name, key := range debugKeys {
+		if value := ctx.Value(key); value != nil {
+			values[name] = value
+		}
+	}
+
+	return values
+}
+
+// ValuePolicy determines how a value key is treated when deriving contexts
+// (see SetValuePolicy()).
+type ValuePolicy int
+
+const (
+	// ValueInherit makes values visible in all derived contexts, like with
+	// the standard library. This is the default.
+	ValueInherit ValuePolicy = iota
+
+	// ValueNonInheritable strips values across detachment boundaries (see
+	// Detach() and CloneDetachedWait()), so security-sensitive values do not
+	// silently flow into background work. Values set again below the
+	// boundary are visible as usual.
+	ValueNonInheritable
+
+	// ValueRequired makes deriving a context that does not carry a value for
+	// the key a misuse (see SetMisusePolicy()).
+	ValueRequired
+)
+
+var (
+	valuePoliciesMu sync.Mutex
+	valuePolicies   atomic.Pointer[map[any]ValuePolicy]
+)
+
+// SetValuePolicy sets the policy for the given value key. It applies to
+// contexts derived after it is set.
+func SetValuePolicy(key any, policy ValuePolicy) {
+	valuePoliciesMu.Lock()
+	defer valuePoliciesMu.Unlock()
+
+	policies := make(map[any]ValuePolicy)
+	if current := valuePolicies.Load(); current != nil {
+		for k, p := range *current {
+			policies[k] = p
+		}
+	}
+
+	if policy == ValueInherit {
+		delete(policies, key)
+	} else {
+		policies[key] = policy
+	}
+
+	if len(policies) == 0 {
+		valuePolicies.Store(nil)
+		return
+	}
+
+	valuePolicies.Store(&policies)
+}
+
+// checkRequiredValues reports a misuse for every required key without a
+// value in c. It must be called by newCtx.
+func checkRequiredValues(c *ctxImpl) {
+	policies := valuePolicies.Load()
+	if policies == nil {
+		return
+	}
+
+	for key, policy := range *policies {
+		if policy == ValueRequired && c.Context.Value(key) == nil {
+			misuse(c, "derived context %v without required value for key %v", c, key)
+		}
+	}
+}
+
+// strippedCtx hides values with the ValueNonInheritable policy.
+type strippedCtx struct {
+	context.Context
+}
+
+func (s strippedCtx) Value(key any) any {
+	if policies := valuePolicies.Load(); policies != nil && (*policies)[key] == ValueNonInheritable {
+		return nil
+	}
+
+	return s.Context.Value(key)
+}
+
+func (s strippedCtx) String() string {
+	return contextName(s.Context)
+}
+
+// stripValues returns a copy of ctx without ValueNonInheritable values.
+func stripValues(ctx context.Context) context.Context {
+	if valuePolicies.Load() == nil {
+		return ctx
+	}
+
+	return strippedCtx{ctx}
+}
+
+// Detach returns a copy of parent for background work that must outlive it:
+// it is never canceled and has no deadline (see context.WithoutCancel()), its
+// wait state is independent (see CloneDetachedWait()) and values with the
+// ValueNonInheritable policy are stripped.
+func Detach(parent Context) Context {
+	c := newCtx(stripValues(context.WithoutCancel(stdContext(parent))), parent)
+	c.detached = true
+
+	derived(parent, c, KindOther)
+
+	return c
+}
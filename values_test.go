@@ -0,0 +1,121 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type valuesKey string
+
+func TestWithValues(t *testing.T) {
+	parent := WithValues(Background(), valuesKey("a"), 1)
+
+	ctx := WithValues(parent,
+		valuesKey("b"), 2,
+		valuesKey("c"), 3,
+		valuesKey("b"), 4,
+	)
+
+	for key, expected := range map[valuesKey]any{
+		"a": 1,
+		"b": 4,
+		"c": 3,
+		"d": nil,
+	} {
+		if value := ctx.Value(key); value != expected {
+			t.Errorf("Expected value for %q to be %v. Got %v.", key, expected, value)
+		}
+	}
+
+	if ctx.Parent() != parent {
+		t.Errorf("Expected parent to be %v. Got %v.", parent, ctx.Parent())
+	}
+
+	if s := fmt.Sprint(ctx); !strings.Contains(s, ".WithValues(b, c, b)") {
+		t.Errorf("Expected %q to contain the keys.", s)
+	}
+}
+
+func TestWithValues_Odd(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic.")
+		}
+	}()
+
+	WithValues(Background(), valuesKey("a"))
+}
+
+func TestDebugValues(t *testing.T) {
+	RegisterDebugKey("request-id", valuesKey("request-id"))
+	RegisterDebugKey("user", valuesKey("user"))
+
+	ctx := WithValues(Background(),
+		valuesKey("request-id"), "1234",
+		valuesKey("secret"), "hunter2",
+	)
+
+	values := DebugValues(ctx)
+	if len(values) != 1 {
+		t.Errorf("Expected 1 value. Got %v.", values)
+	}
+	if value := values["request-id"]; value != "1234" {
+		t.Errorf("Expected request-id to be %q. Got %v.", "1234", value)
+	}
+}
+
+func TestSetValuePolicy_NonInheritable(t *testing.T) {
+	key := valuesKey("token")
+
+	SetValuePolicy(key, ValueNonInheritable)
+	defer SetValuePolicy(key, ValueInherit)
+
+	parent, cancel := WithCancel(WithValues(Background(), key, "secret"))
+	defer cancel()
+
+	if value := WithName(parent, "child").Value(key); value != "secret" {
+		t.Errorf("Expected value to be inherited by regular children. Got %v.", value)
+	}
+
+	detached := Detach(parent)
+	if value := detached.Value(key); value != nil {
+		t.Errorf("Expected value to be stripped. Got %v.", value)
+	}
+	if value := CloneDetachedWait(parent).Value(key); value != nil {
+		t.Errorf("Expected value to be stripped. Got %v.", value)
+	}
+	if value := WithValues(detached, key, "other").Value(key); value != "other" {
+		t.Errorf("Expected value set below the boundary to be visible. Got %v.", value)
+	}
+
+	cancel()
+
+	if detached.Err() != nil {
+		t.Errorf("Expected detached context not to be canceled. Got %v.", detached.Err())
+	}
+}
+
+func TestSetValuePolicy_Required(t *testing.T) {
+	key := valuesKey("tenant")
+
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	SetValuePolicy(key, ValueRequired)
+	ctx := WithName(Background(), "missing")
+	SetValuePolicy(key, ValueInherit)
+
+	if err := Misuse(ctx); !errors.Is(err, ErrMisuse) {
+		t.Errorf("Expected misuse error. Got %v.", err)
+	}
+
+	SetValuePolicy(key, ValueRequired)
+	ctx = WithName(WithValues(Background(), key, "acme"), "present")
+	SetValuePolicy(key, ValueInherit)
+
+	if err := Misuse(ctx); err != nil {
+		t.Errorf("Expected no misuse error. Got %v.", err)
+	}
+}
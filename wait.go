@@ -0,0 +1,273 @@
+package context
+
+import (
+	"sync"
+	"time"
+)
+
+// waitGroup is similar to a sync.WaitGroup but it also keeps track of its
+// counter so it can be inspected and supports running functions when the
+// counter reaches zero.
+//
+// Work is tracked in rounds. A round starts when the counter goes from zero
+// to positive and ends when it goes back to zero. Contrary to a
+// sync.WaitGroup, there are no restrictions on reuse: a new round can be
+// started at any time (even while waiters of the previous one are still
+// being released) and Wait() can be called concurrently from any number of
+// goroutines. Waiters are released when the round that was current when they
+// started waiting ends.
+type waitGroup struct {
+	mu sync.Mutex
+
+	n int
+
+	// Closed when the counter reaches zero and all after functions finished
+	// running. It is only created when needed (somebody is waiting or there
+	// are after functions to run) and is nil if there is nothing pending.
+	done chan struct{}
+
+	after []func()
+
+	// Number of registrations and Done() calls in the current round.
+	total    int
+	finished int
+
+	// Closed (and reset) after every Done() call if there is anybody
+	// tracking progress.
+	progress chan struct{}
+
+	// Number of rounds that ended and the total number of registrations of
+	// the last one.
+	rounds    uint64
+	doneTotal int
+
+	// Time (in Unix nanoseconds) the counter last went back to zero. Zero if
+	// it never did while wait timings were enabled (see SetWaitTimings()).
+	finishedAt int64
+
+	// Number of registrations removed with detach() whose owners might still
+	// call Done(). Those calls are ignored if they would make the counter
+	// negative.
+	detached int
+}
+
+func (wg *waitGroup) Add(delta int) {
+	wg.mu.Lock()
+
+	if wg.n == 0 && delta > 0 {
+		// New round of work. If the previous round is still finishing, its
+		// waiters keep waiting on its own channel.
+		wg.done = nil
+		wg.total = 0
+		wg.finished = 0
+	}
+
+	wg.n += delta
+	if wg.n < 0 {
+		if wg.detached < -wg.n {
+			wg.mu.Unlock()
+			panic("negative wait counter")
+		}
+
+		// Late Done() calls for detached registrations.
+		wg.detached += wg.n
+		delta -= wg.n
+		wg.n = 0
+
+		if delta == 0 {
+			wg.mu.Unlock()
+			return
+		}
+	}
+
+	if delta > 0 {
+		wg.total += delta
+	} else {
+		wg.finished -= delta
+		if wg.progress != nil {
+			close(wg.progress)
+			wg.progress = nil
+		}
+	}
+
+	if wg.n == 0 && delta < 0 {
+		if timingsEnabled() {
+			wg.finishedAt = time.Now().UnixNano()
+		}
+		wg.finish()
+		return
+	}
+
+	wg.mu.Unlock()
+}
+
+func (wg *waitGroup) Done() {
+	wg.Add(-1)
+}
+
+func (wg *waitGroup) Wait() {
+	wg.mu.Lock()
+
+	if wg.n == 0 && wg.done == nil {
+		// Nothing pending. Still give a chance to any after functions to run.
+		wg.finish()
+		return
+	}
+
+	done := wg.doneChan()
+	wg.mu.Unlock()
+
+	<-done
+}
+
+// waitUntil is like Wait() but gives up when abort is closed. It returns true
+// if the wait completed.
+func (wg *waitGroup) waitUntil(abort <-chan struct{}) bool {
+	wg.mu.Lock()
+
+	if wg.n == 0 && wg.done == nil {
+		wg.finish()
+		return true
+	}
+
+	done := wg.doneChan()
+	wg.mu.Unlock()
+
+	select {
+	case <-done:
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+// doneChan returns the channel that is closed when the current round ends,
+// creating it if needed. It must be called with the lock held.
+func (wg *waitGroup) doneChan() chan struct{} {
+	if wg.done == nil {
+		wg.done = make(chan struct{})
+	}
+
+	return wg.done
+}
+
+// waitProgress is like Wait() but calls report with the number of finished
+// and total registrations every time they change.
+func (wg *waitGroup) waitProgress(report func(finished, total int)) {
+	lastFinished, lastTotal := -1, -1
+
+	for {
+		wg.mu.Lock()
+
+		finished, total, rounds := wg.finished, wg.total, wg.rounds
+
+		var done, progress chan struct{}
+		if wg.n > 0 || wg.done != nil {
+			done = wg.doneChan()
+			if wg.progress == nil {
+				wg.progress = make(chan struct{})
+			}
+			progress = wg.progress
+		}
+
+		wg.mu.Unlock()
+
+		if finished != lastFinished || total != lastTotal {
+			report(finished, total)
+			lastFinished, lastTotal = finished, total
+		}
+
+		if done == nil {
+			// Nothing pending.
+			wg.Wait()
+			return
+		}
+
+		select {
+		case <-progress:
+		case <-done:
+			wg.mu.Lock()
+			if wg.rounds == rounds+1 {
+				total = wg.doneTotal
+			}
+			wg.mu.Unlock()
+
+			// A new round might already have started, so do not rely on
+			// the current counts.
+			if total != lastFinished || total != lastTotal {
+				report(total, total)
+			}
+
+			return
+		}
+	}
+}
+
+// detach removes count registrations as if Done() was called for them,
+// without requiring their owners to ever call it.
+func (wg *waitGroup) detach(count int) {
+	wg.mu.Lock()
+	wg.detached += count
+	wg.mu.Unlock()
+
+	wg.Add(-count)
+}
+
+func (wg *waitGroup) count() int {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+
+	return wg.n
+}
+
+// lastFinished returns the time the counter last went back to zero or the
+// zero time if it never did.
+func (wg *waitGroup) lastFinished() time.Time {
+	wg.mu.Lock()
+	finishedAt := wg.finishedAt
+	wg.mu.Unlock()
+
+	if finishedAt == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, finishedAt)
+}
+
+func (wg *waitGroup) afterDone(fn func()) {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+
+	wg.after = append(wg.after, fn)
+}
+
+// finish runs all pending after functions and then releases any waiters. It
+// must be called with the lock held and releases it.
+func (wg *waitGroup) finish() {
+	wg.rounds++
+	wg.doneTotal = wg.total
+
+	if wg.done == nil && len(wg.after) == 0 {
+		// Fast path. Nobody to notify.
+		wg.mu.Unlock()
+		return
+	}
+
+	// Anybody that starts waiting while the after functions run must wait
+	// for them.
+	done, after := wg.doneChan(), wg.after
+	wg.after = nil
+	wg.mu.Unlock()
+
+	for _, fn := range after {
+		fn()
+	}
+
+	wg.mu.Lock()
+	if wg.done == done {
+		wg.done = nil
+	}
+	wg.mu.Unlock()
+
+	close(done)
+}
@@ -0,0 +1,195 @@
+package context
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAfterChildrenFinished(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	var order []int
+	finished := false
+
+	parent.AfterChildrenFinished(func() {
+		if !finished {
+			t.Errorf("Expected after function to run after child finished.")
+		}
+		order = append(order, 1)
+	})
+	parent.AfterChildrenFinished(func() {
+		order = append(order, 2)
+	})
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		finished = true
+		ctx.Finished()
+	}(EnableWait(ctx))
+
+	parent.WaitForChildren()
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("Expected after functions to run in order. Got %v.", order)
+	}
+
+	// Functions only run once.
+	parent.WaitForChildren()
+
+	if len(order) != 2 {
+		t.Errorf("Expected after functions to run once. Got %v.", order)
+	}
+}
+
+func TestAfterChildrenFinished_NoChildren(t *testing.T) {
+	parent := Background()
+
+	called := false
+	parent.AfterChildrenFinished(func() {
+		called = true
+	})
+
+	parent.WaitForChildren()
+
+	if !called {
+		t.Errorf("Expected after function to be called.")
+	}
+}
+
+func TestWaitGroup_Negative(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected negative counter to panic.")
+		}
+	}()
+
+	var wg waitGroup
+	wg.Done()
+}
+
+func TestWaitForChildrenProgress(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	const numChildren = 3
+
+	proceed := make(chan struct{})
+
+	for i := 0; i < numChildren; i++ {
+		go func(ctx Context) {
+			<-proceed
+			ctx.Finished()
+		}(EnableWait(ctx))
+	}
+
+	var reports [][2]int
+	WaitForChildrenProgress(parent, func(finished, total int) {
+		reports = append(reports, [2]int{finished, total})
+		if finished == 0 {
+			close(proceed)
+		}
+	})
+
+	if len(reports) == 0 || reports[0] != [2]int{0, numChildren} {
+		t.Fatalf("Expected first report to be {0 %d}. Got %v.", numChildren, reports)
+	}
+	if last := reports[len(reports)-1]; last != [2]int{numChildren, numChildren} {
+		t.Errorf("Expected last report to be {%d %d}. Got %v.", numChildren, numChildren, last)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i][0] <= reports[i-1][0] {
+			t.Errorf("Expected progress to increase. Got %v.", reports)
+		}
+	}
+}
+
+func TestWait_SequentialWaves(t *testing.T) {
+	parent := Background()
+
+	for wave := 0; wave < 10; wave++ {
+		ctx, cancel := WithCancel(parent)
+
+		var finished atomic.Int32
+		for i := 0; i < 10; i++ {
+			go func(ctx Context) {
+				finished.Add(1)
+				ctx.Finished()
+			}(EnableWait(ctx))
+		}
+
+		parent.WaitForChildren()
+		cancel()
+
+		if n := finished.Load(); n != 10 {
+			t.Fatalf("Expected 10 finished children in wave %d. Got %d.", wave, n)
+		}
+	}
+}
+
+func TestWait_ConcurrentWaiters(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	EnableWait(ctx)
+
+	var released atomic.Int32
+	var waiters sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		waiters.Add(1)
+		go func() {
+			defer waiters.Done()
+
+			parent.WaitForChildren()
+			released.Add(1)
+		}()
+	}
+
+	time.Sleep(1 * time.Millisecond)
+
+	if n := released.Load(); n != 0 {
+		t.Errorf("Expected no released waiters. Got %d.", n)
+	}
+
+	ctx.Finished()
+	waiters.Wait()
+
+	if n := released.Load(); n != 10 {
+		t.Errorf("Expected 10 released waiters. Got %d.", n)
+	}
+}
+
+func TestWait_OverlappingWaves(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	// Waiters and new registrations constantly overlap.
+	var waiters sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		EnableWait(ctx)
+
+		waiters.Add(1)
+		go func() {
+			defer waiters.Done()
+			parent.WaitForChildren()
+		}()
+
+		go ctx.Finished()
+	}
+
+	waiters.Wait()
+
+	if n := WaitCount(ctx); n != 0 {
+		t.Errorf("Expected wait count to be 0. Got %d.", n)
+	}
+}
@@ -0,0 +1,94 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StalledError is the cancellation cause (see context.Cause()) of contexts
+// canceled by a watchdog (see WithWatchdog()).
+type StalledError struct {
+	// How long the context went without being touched.
+	Idle time.Duration
+}
+
+func (e *StalledError) Error() string {
+	return fmt.Sprintf("context stalled: no progress for %v", e.Idle)
+}
+
+// watchdog cancels a context if it is not touched for a while.
+type watchdog struct {
+	// Enclosing watchdog, if any.
+	parent *watchdog
+
+	idle   time.Duration
+	clock  Clock
+	done   <-chan struct{}
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	touched time.Time
+}
+
+// WithWatchdog returns a copy of parent that is canceled, with a
+// *StalledError cause, if neither it nor any context derived from it calls
+// Touch() for idle. This protects pipelines from silent hangs.
+func WithWatchdog(parent Context, idle time.Duration) (Context, CancelFunc) {
+	c, cancel := WithCancel(parent)
+
+	n := nodeOf(c)
+
+	w := &watchdog{
+		parent:  n.config().watchdog,
+		idle:    idle,
+		clock:   n.getClock(),
+		done:    c.Done(),
+		cancel:  lookup(c).cancelWith,
+		touched: n.getClock().Now(),
+	}
+
+	n.setInherited(func(i *inherited) {
+		i.watchdog = w
+	})
+	w.arm(idle)
+
+	return c, cancel
+}
+
+func (w *watchdog) arm(d time.Duration) {
+	w.clock.AfterFunc(d, w.check)
+}
+
+// check cancels the context if it was not touched for idle or rearms the
+// watchdog otherwise.
+func (w *watchdog) check() {
+	select {
+	case <-w.done:
+		// Nothing to watch anymore.
+		return
+	default:
+	}
+
+	w.mu.Lock()
+	idle := w.clock.Now().Sub(w.touched)
+	w.mu.Unlock()
+
+	if idle >= w.idle {
+		w.cancel(&StalledError{idle})
+		return
+	}
+
+	w.arm(w.idle - idle)
+}
+
+func (n *node) Touch() {
+	for w := n.config().watchdog; w != nil; w = w.parent {
+		now := w.clock.Now()
+
+		w.mu.Lock()
+		w.touched = now
+		w.mu.Unlock()
+	}
+}
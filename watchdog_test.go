@@ -0,0 +1,53 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithWatchdog(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	ctx, cancel := WithWatchdog(WithClock(Background(), clock), time.Minute)
+	defer cancel()
+
+	child := WithName(ctx, "child")
+
+	// Progress was made, so the watchdog is rearmed on expiration.
+	clock.now = clock.now.Add(30 * time.Second)
+	child.Touch()
+	clock.now = clock.now.Add(30 * time.Second)
+	clock.fire()
+
+	if ctx.Err() != nil {
+		t.Fatalf("Expected context not to be canceled. Got %v.", ctx.Err())
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	clock.fire()
+
+	if ctx.Err() != Canceled {
+		t.Errorf("Expected context to be canceled. Got %v.", ctx.Err())
+	}
+
+	var stalled *StalledError
+	if !errors.As(stdcontext.Cause(child), &stalled) {
+		t.Fatalf("Expected cause to be a StalledError. Got %v.", stdcontext.Cause(child))
+	}
+	if stalled.Idle != 90*time.Second {
+		t.Errorf("Expected idle to be %v. Got %v.", 90*time.Second, stalled.Idle)
+	}
+}
+
+func TestWithWatchdog_RealClock(t *testing.T) {
+	ctx, cancel := WithWatchdog(Background(), 5*time.Millisecond)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Expected watchdog to cancel the context.")
+	}
+}
@@ -0,0 +1,274 @@
+package context
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// SetWaitCapacity sets the total weight available to children of ctx
+// registered with EnableWaitWeighted(). Registrations block while the sum of
+// the weights of unfinished children would exceed capacity. This allows
+// budgeting memory, CPU, etc when fanning out work.
+func SetWaitCapacity(ctx Context, capacity int64) {
+	n := nodeOf(ctx)
+	if n == nil {
+		misuse(ctx, "SetWaitCapacity() called on context %v without wait support", ctx)
+		return
+	}
+
+	n.ext().capacity.Store(newSemaphore(capacity))
+}
+
+// EnableWaitWeighted is like EnableWait but the registration consumes weight
+// from the capacity of the parent of ctx (see SetWaitCapacity()). It blocks
+// until enough capacity is available or ctx is done, in which case it returns
+// the ctx error and nothing is registered. If the parent has no capacity set,
+// it never blocks. If the registration fails after the weight was acquired
+// (for example, because of the parent limit, see SetMaxChildren()), the
+// misuse is reported (see SetMisusePolicy()), the weight is released and the
+// error is returned.
+//
+// It returns a copy of ctx that must be used to report completion (by calling
+// Finished() on it exactly once), at which point the weight is released.
+func EnableWaitWeighted(ctx Context, weight int64) (Context, error) {
+	n := nodeOf(ctx)
+	if n == nil || n.pNode() == nil {
+		return ctx, misuse(ctx, "EnableWaitWeighted() called on root context %v", ctx)
+	}
+
+	target := n.waitOn
+	if target == nil {
+		target = n.parent
+	}
+
+	// The weight is released to the semaphore it was acquired from, even if
+	// the capacity of the parent changes in the meantime.
+	p := n.pNode()
+	var sem *semaphore
+	if x := p.extras.Load(); x != nil {
+		sem = x.capacity.Load()
+	}
+	if sem != nil && weight > 0 {
+		if err := sem.acquire(ctx, weight); err != nil {
+			return nil, err
+		}
+	} else {
+		sem = nil
+	}
+
+	c := newCtx(stdContext(ctx), ctx)
+	c.waitOn = target
+	if sem != nil {
+		c.ext().weight = &heldWeight{sem, weight}
+	}
+	c.name = n.name
+
+	wc, err := enableWait(c, 1, "EnableWaitWeighted", false)
+	if err != nil {
+		// Not registered, so Finished() will never release it.
+		if sem != nil {
+			sem.release(weight)
+		}
+
+		return nil, err
+	}
+
+	return wc, nil
+}
+
+// heldWeight is the weight a child holds and the semaphore it was acquired
+// from.
+type heldWeight struct {
+	sem *semaphore
+	n   int64
+}
+
+// semaphore is a weighted semaphore, similar to the one in
+// golang.org/x/sync/semaphore. Waiters are served in FIFO order unless it is
+// fair, in which case they are served round-robin across tenants (and in FIFO
+// order within each tenant).
+type semaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List
+
+	// Round-robin state. The tenant served least recently (or never) goes
+	// first.
+	fair   bool
+	turn   uint64
+	served map[string]uint64
+}
+
+type semaphoreWaiter struct {
+	n      int64
+	tenant string
+	ready  chan struct{}
+}
+
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{
+		size: size,
+	}
+}
+
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	return s.acquireAs(ctx, n, "")
+}
+
+// acquireAs is like acquire() but waits on behalf of the given tenant.
+func (s *semaphore) acquireAs(ctx context.Context, n int64, tenant string) error {
+	done := ctx.Done()
+
+	s.mu.Lock()
+
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	// Only fail right away if it would block, so a done ctx does not
+	// prevent acquiring what is available.
+	select {
+	case <-done:
+		s.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+
+	if n > s.size {
+		// Can never succeed.
+		s.mu.Unlock()
+		<-done
+
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, tenant: tenant, ready: ready})
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-done:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-ready:
+			// Acquired after being canceled. Give it back.
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if (isFront || s.fair) && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+
+		return ctx.Err()
+	}
+}
+
+// tryAcquire acquires n without blocking. It returns false if that is not
+// possible.
+func (s *semaphore) tryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+
+	return false
+}
+
+func (s *semaphore) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	if s.cur < 0 {
+		panic("released more weight than held")
+	}
+
+	s.notifyWaiters()
+}
+
+// setFair enables or disables round-robin service across tenants.
+func (s *semaphore) setFair(fair bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fair = fair
+	s.notifyWaiters()
+}
+
+// notifyWaiters wakes up waiters in order while there is enough capacity. It
+// must be called with the lock held.
+func (s *semaphore) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if s.fair {
+			next = s.nextFair()
+		}
+		if next == nil {
+			return
+		}
+
+		w := next.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// Not enough capacity for the next waiter. Keep the service order.
+			return
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+
+		if s.fair {
+			s.turn++
+			s.served[w.tenant] = s.turn
+		}
+	}
+}
+
+// nextFair returns the oldest waiter of the tenant served least recently. It
+// must be called with the lock held.
+func (s *semaphore) nextFair() *list.Element {
+	if s.served == nil {
+		s.served = make(map[string]uint64)
+	}
+
+	var next *list.Element
+	var nextServed uint64
+
+	waiting := make(map[string]bool)
+	for e := s.waiters.Front(); e != nil; e = e.Next() {
+		tenant := e.Value.(semaphoreWaiter).tenant
+		if waiting[tenant] {
+			continue
+		}
+		waiting[tenant] = true
+
+		if served := s.served[tenant]; next == nil || served < nextServed {
+			next, nextServed = e, served
+		}
+	}
+
+	// Forget tenants that are not waiting anymore.
+	for tenant := range s.served {
+		if !waiting[tenant] {
+			delete(s.served, tenant)
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,134 @@
+package context
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnableWaitWeighted(t *testing.T) {
+	parent := Background()
+	SetWaitCapacity(parent, 10)
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	var inUse, maxInUse atomic.Int64
+
+	for i := 0; i < 10; i++ {
+		weight := int64(i%3 + 3)
+
+		child, err := EnableWaitWeighted(ctx, weight)
+		if err != nil {
+			t.Fatalf("Unexpected error %v.", err)
+		}
+
+		go func(ctx Context) {
+			defer ctx.Finished()
+
+			n := inUse.Add(weight)
+			for {
+				m := maxInUse.Load()
+				if n <= m || maxInUse.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(1 * time.Millisecond)
+			inUse.Add(-weight)
+		}(child)
+	}
+
+	parent.WaitForChildren()
+
+	if n := maxInUse.Load(); n > 10 {
+		t.Errorf("Expected at most 10 weight in use. Got %d.", n)
+	}
+}
+
+func TestEnableWaitWeighted_Canceled(t *testing.T) {
+	parent := Background()
+	SetWaitCapacity(parent, 1)
+
+	ctx, cancel := WithCancel(parent)
+
+	held, err := EnableWaitWeighted(ctx, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	go func() {
+		time.Sleep(1 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := EnableWaitWeighted(ctx, 1); err != Canceled {
+		t.Errorf("Expected error to be %v. Got %v.", Canceled, err)
+	}
+
+	held.Finished()
+	parent.WaitForChildren()
+}
+
+func TestEnableWaitWeighted_CapacityChanged(t *testing.T) {
+	parent := Background()
+
+	// Acquired with no capacity set.
+	unlimited, err := EnableWaitWeighted(WithName(parent, "unlimited"), 5)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	SetWaitCapacity(parent, 10)
+
+	limited, err := EnableWaitWeighted(WithName(parent, "limited"), 5)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	SetWaitCapacity(parent, 10)
+
+	// Neither must release weight to the current capacity.
+	unlimited.Finished()
+	limited.Finished()
+
+	parent.WaitForChildren()
+}
+
+func TestEnableWaitWeighted_Rejected(t *testing.T) {
+	SetMisusePolicy(MisuseError)
+	defer SetMisusePolicy(MisusePanic)
+
+	if _, err := EnableWaitWeighted(Background(), 1); err == nil {
+		t.Errorf("Expected error for a root context.")
+	}
+
+	parent := Background()
+	SetWaitCapacity(parent, 2)
+	SetMaxChildren(parent, 1, false)
+
+	first, err := EnableWaitWeighted(WithName(parent, "first"), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+
+	if _, err := EnableWaitWeighted(WithName(parent, "second"), 1); err == nil || !strings.Contains(err.Error(), ErrTooManyChildren.Error()) {
+		t.Errorf("Expected misuse caused by %v. Got %v.", ErrTooManyChildren, err)
+	}
+
+	first.Finished()
+
+	// All the weight must be available again, so this does not block (and
+	// fail, as ctx is done).
+	ctx, cancel := WithCancel(parent)
+	cancel()
+
+	all, err := EnableWaitWeighted(ctx, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error %v.", err)
+	}
+	all.Finished()
+
+	parent.WaitForChildren()
+}
@@ -0,0 +1,34 @@
+package context
+
+// Wrapper is meant to be embedded by types that wrap a Context to add extra
+// behavior to it (logging, tracing, etc). All methods are forwarded to the
+// wrapped Context, including the wait related ones, so embedders only need to
+// implement the methods they want to change. For example:
+//
+//	type loggingCtx struct {
+//		context.Wrapper
+//	}
+//
+//	func (c loggingCtx) Finished() {
+//		log.Print("finished")
+//		c.Wrapper.Finished()
+//	}
+//
+// Contexts derived from the wrapper (with WithCancel(), etc) use the wrapper
+// as their parent and correctly report to the wrapped Context wait state.
+//
+// Embedders that override Value() must forward any keys they do not handle to
+// the Wrapper, as that is how the wait state is found.
+type Wrapper struct {
+	Context
+}
+
+// Wrapped returns the wrapped Context.
+func (w Wrapper) Wrapped() Context {
+	return w.Context
+}
+
+// String returns the description of the wrapped Context.
+func (w Wrapper) String() string {
+	return contextName(w.Context)
+}
@@ -0,0 +1,86 @@
+package context
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingCtx struct {
+	Wrapper
+
+	finished *atomic.Int32
+}
+
+func (c countingCtx) Finished() {
+	c.finished.Add(1)
+	c.Wrapper.Finished()
+}
+
+func (c countingCtx) Value(key any) any {
+	if key == "wrapped" {
+		return true
+	}
+
+	return c.Wrapper.Value(key)
+}
+
+func TestWrapper(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	finished := &atomic.Int32{}
+	wrapped := countingCtx{Wrapper{ctx}, finished}
+
+	value := 0
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		value = 1
+		ctx.Finished()
+	}(EnableWait(wrapped))
+
+	parent.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+	if n := finished.Load(); n != 1 {
+		t.Errorf("Expected wrapper Finished() to be called once. Got %d.", n)
+	}
+	if wrapped.Wrapped() != ctx {
+		t.Errorf("Expected wrapped context to be %v. Got %v.", ctx, wrapped.Wrapped())
+	}
+}
+
+func TestWrapper_Derived(t *testing.T) {
+	parent := Background()
+
+	ctx, cancel := WithCancel(parent)
+	defer cancel()
+
+	wrapped := countingCtx{Wrapper{ctx}, &atomic.Int32{}}
+
+	child, cancel := WithCancel(wrapped)
+	defer cancel()
+
+	if child.Value("wrapped") != true {
+		t.Errorf("Expected derived context to see wrapper values.")
+	}
+
+	value := 0
+
+	go func(ctx Context) {
+		time.Sleep(1 * time.Millisecond)
+		value = 1
+		ctx.Finished()
+	}(EnableWait(child))
+
+	ctx.WaitForChildren()
+
+	if value != 1 {
+		t.Errorf("Expected value to be 1. Got %d.", value)
+	}
+}